@@ -0,0 +1,121 @@
+package runnable
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxAuditLineSize is the maximum size of a line of an audit log.
+const maxAuditLineSize = 1 << 20
+
+// AuditFilter selects audit entries. The zero AuditFilter selects every entry.
+type AuditFilter struct {
+	// ID selects the entries of the runnable with the given ID and of the runnables
+	// of its group, e.g. "api" selects "api" and "api/http".
+	ID string
+	// Since and Until select the entries logged at or after Since, and before Until.
+	Since time.Time
+	Until time.Time
+}
+
+// Match returns true if the filter selects entry.
+func (f AuditFilter) Match(entry AuditEntry) bool {
+	if f.ID != "" && entry.ID != f.ID && !strings.HasPrefix(entry.ID, f.ID+"/") {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Time.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// ReadAuditLog reads the entries of the audit log at path that match filter,
+// oldest first. The files rotated by OpenAuditLog, path.N to path.1, are read
+// before path. It also returns the number of malformed lines that were skipped,
+// see ReadAuditEntries.
+//
+// Example:
+//
+//	entries, skipped, err := runnable.ReadAuditLog("/var/log/app/audit.jsonl", runnable.AuditFilter{
+//		ID:    "api",
+//		Since: time.Now().Add(-time.Hour),
+//	})
+func ReadAuditLog(path string, filter AuditFilter) ([]AuditEntry, int, error) {
+	paths := []string{path}
+	for i := 1; ; i++ {
+		backup := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(backup); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+			return nil, 0, err
+		}
+		paths = append([]string{backup}, paths...)
+	}
+
+	var (
+		entries []AuditEntry
+		skipped int
+	)
+	for _, p := range paths {
+		file, err := os.Open(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		fileEntries, fileSkipped, err := ReadAuditEntries(file, filter)
+		file.Close()
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", p, err)
+		}
+		entries = append(entries, fileEntries...)
+		skipped += fileSkipped
+	}
+
+	return entries, skipped, nil
+}
+
+// ReadAuditEntries reads the entries written by an AuditLog to r that match
+// filter. Malformed lines, e.g. torn by a crash in the middle of a write, are
+// skipped, and their number is returned along with the entries.
+func ReadAuditEntries(r io.Reader, filter AuditFilter) ([]AuditEntry, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxAuditLineSize)
+
+	var (
+		entries []AuditEntry
+		skipped int
+	)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			skipped++
+			continue
+		}
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	return entries, skipped, nil
+}
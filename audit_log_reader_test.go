@@ -0,0 +1,80 @@
+package runnable
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAuditLog(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	var buf bytes.Buffer
+	audit := NewAuditLog(&buf)
+	audit.Log(AuditEntry{Time: now, ID: "api", Event: AuditRunStarted})
+	audit.Log(AuditEntry{Time: now.Add(time.Second), ID: "api/http", Event: AuditRunStarted})
+	audit.Log(AuditEntry{Time: now.Add(2 * time.Second), ID: "apiv2", Event: AuditRunStarted})
+	audit.Log(AuditEntry{Time: now.Add(3 * time.Second), ID: "api", Event: AuditRunEnded, Duration: 3 * time.Second, Error: "failed"})
+	require.NoError(t, audit.Err())
+	data := buf.String()
+
+	t.Run("filter", func(t *testing.T) {
+		read := func(filter AuditFilter) []string {
+			entries, skipped, err := ReadAuditEntries(strings.NewReader(data), filter)
+			require.NoError(t, err)
+			require.Zero(t, skipped)
+
+			var ids []string
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+			return ids
+		}
+
+		assert.Equal(t, []string{"api", "api/http", "apiv2", "api"}, read(AuditFilter{}))
+		assert.Equal(t, []string{"api", "api/http", "api"}, read(AuditFilter{ID: "api"}))
+		assert.Equal(t, []string{"api/http"}, read(AuditFilter{ID: "api/http"}))
+		assert.Equal(t, []string{"api/http", "apiv2"}, read(AuditFilter{Since: now.Add(time.Second), Until: now.Add(3 * time.Second)}))
+		assert.Equal(t, []string{"api"}, read(AuditFilter{ID: "api", Since: now.Add(2 * time.Second)}))
+	})
+
+	t.Run("entry", func(t *testing.T) {
+		entries, _, err := ReadAuditEntries(strings.NewReader(data), AuditFilter{Since: now.Add(3 * time.Second)})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.True(t, now.Add(3*time.Second).Equal(entries[0].Time))
+		assert.Equal(t, AuditRunEnded, entries[0].Event)
+		assert.Equal(t, 3*time.Second, entries[0].Duration)
+		assert.Equal(t, "failed", entries[0].Error)
+	})
+
+	t.Run("truncated last line", func(t *testing.T) {
+		entries, skipped, err := ReadAuditEntries(strings.NewReader(data+`{"time":"20`), AuditFilter{})
+		require.NoError(t, err)
+		assert.Len(t, entries, 4)
+		assert.Equal(t, 1, skipped)
+	})
+
+	t.Run("malformed lines", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+		torn := `{"time":"20` + lines[0] + "\n" + lines[1] + "\n" + "not json\n" + lines[2] + "\n" + lines[3] + "\n"
+
+		entries, skipped, err := ReadAuditEntries(strings.NewReader(torn), AuditFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, skipped)
+		require.Len(t, entries, 3)
+		assert.Equal(t, "api/http", entries[0].ID)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		entries, skipped, err := ReadAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"), AuditFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+		assert.Zero(t, skipped)
+	})
+}
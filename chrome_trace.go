@@ -0,0 +1,322 @@
+package runnable
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChromeTraceRecorder is a Tracer that records the lifecycle of runnables and
+// writes it in the Chrome Trace Event format, to be opened in Perfetto
+// (https://ui.perfetto.dev) or chrome://tracing. Each runnable is a track named
+// after its ID, with its runs and their attempts as nested slices, and its panics
+// as instant events. The spans started by runFunc are on the track of their
+// runnable.
+//
+// The recorder keeps the spans that are running and a bounded number of the last
+// spans that ended, so that its memory stays bounded in long-running processes
+// and restart storms.
+type ChromeTraceRecorder struct {
+	pid int
+
+	maxSpans int
+	nextID   int
+	running  map[int]*chromeTraceSpan
+	ended    *ring[*chromeTraceSpan]
+
+	mu sync.Mutex
+}
+
+var _ Tracer = (*ChromeTraceRecorder)(nil)
+
+type chromeTraceSpan struct {
+	recorder *ChromeTraceRecorder
+
+	// the fields below are guarded by the mutex of the recorder
+	id   int
+	name string
+	// track is the ID of the runnable of the span, or of its closest parent that has one
+	track     string
+	attrs     map[string]interface{}
+	errs      []error
+	startTime time.Time
+	endTime   time.Time
+	ended     bool
+}
+
+type chromeTraceSpanKey struct{}
+
+// NewChromeTraceRecorder creates a new ChromeTraceRecorder that keeps the last
+// maxSpans spans that ended, along with the spans that are running. Older spans
+// are dropped from the trace.
+//
+// Example:
+//
+//	recorder := runnable.NewChromeTraceRecorder(10000)
+//	group := runnable.NewGroupWithOptions([]runnable.Runnable{
+//		runnable.New(serveHTTP, runnable.WithStatus("http", store), runnable.WithTracer(recorder)),
+//		runnable.New(serveGRPC, runnable.WithStatus("grpc", store), runnable.WithTracer(recorder)),
+//	}, runnable.WithStatus("api", store), runnable.WithTracer(recorder))
+//
+//	err := group.Run(ctx)
+//	...
+//	err = recorder.WriteFile("trace.json")
+func NewChromeTraceRecorder(maxSpans int) *ChromeTraceRecorder {
+	return &ChromeTraceRecorder{
+		pid:      os.Getpid(),
+		maxSpans: maxSpans,
+		running:  make(map[int]*chromeTraceSpan),
+		ended:    newRing[*chromeTraceSpan](maxSpans),
+	}
+}
+
+func (c *ChromeTraceRecorder) StartSpan(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, Span) {
+	span := &chromeTraceSpan{
+		recorder:  c,
+		name:      name,
+		attrs:     make(map[string]interface{}, len(attrs)),
+		startTime: time.Now(),
+	}
+	for key, value := range attrs {
+		span.attrs[key] = value
+	}
+
+	c.mu.Lock()
+	if id, ok := attrs["runnable.id"].(string); ok {
+		span.track = id
+	} else if parent, ok := ctx.Value(chromeTraceSpanKey{}).(*chromeTraceSpan); ok && parent.recorder == c {
+		span.track = parent.track
+	}
+	c.nextID++
+	span.id = c.nextID
+	c.running[span.id] = span
+	c.mu.Unlock()
+
+	return context.WithValue(ctx, chromeTraceSpanKey{}, span), span
+}
+
+func (s *chromeTraceSpan) SetAttributes(attrs map[string]interface{}) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	for key, value := range attrs {
+		s.attrs[key] = value
+	}
+	if id, ok := attrs["runnable.id"].(string); ok {
+		s.track = id
+	}
+}
+
+func (s *chromeTraceSpan) RecordError(err error) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	s.errs = append(s.errs, err)
+}
+
+func (s *chromeTraceSpan) End() {
+	c := s.recorder
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.ended = true
+	s.endTime = time.Now()
+
+	// spans discarded by Reset are not recorded
+	if _, ok := c.running[s.id]; ok {
+		delete(c.running, s.id)
+		c.ended.push(s)
+	}
+}
+
+// chromeTraceEvent is an event of the Chrome Trace Event format. Times are in microseconds.
+type chromeTraceEvent struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Phase string                 `json:"ph"`
+	TS    float64                `json:"ts"`
+	Dur   *float64               `json:"dur,omitempty"`
+	PID   int                    `json:"pid"`
+	TID   int                    `json:"tid"`
+	Scope string                 `json:"s,omitempty"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+type chromeTrace struct {
+	TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string             `json:"displayTimeUnit"`
+}
+
+// WriteTo writes the trace recorded so far to w, as JSON. Spans that have not
+// ended yet end at the time of the call, with the "in_progress" argument.
+func (c *ChromeTraceRecorder) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.Marshal(chromeTrace{
+		TraceEvents:     c.events(time.Now()),
+		DisplayTimeUnit: "ms",
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// WriteFile writes the trace recorded so far to the file at path, see WriteTo.
+func (c *ChromeTraceRecorder) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(file)
+	if _, err := c.WriteTo(buf); err != nil {
+		file.Close()
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Reset discards the trace recorded so far.
+func (c *ChromeTraceRecorder) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.running = make(map[int]*chromeTraceSpan)
+	c.ended = newRing[*chromeTraceSpan](c.maxSpans)
+}
+
+// spans returns a copy of the spans recorded so far, in the order they started.
+func (c *ChromeTraceRecorder) spans() []chromeTraceSpan {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	spans := make([]chromeTraceSpan, 0, c.ended.len()+len(c.running))
+	add := func(span *chromeTraceSpan) {
+		cp := *span
+		cp.attrs = make(map[string]interface{}, len(span.attrs))
+		for key, value := range span.attrs {
+			cp.attrs[key] = value
+		}
+		cp.errs = append([]error(nil), span.errs...)
+		spans = append(spans, cp)
+	}
+	for _, span := range c.ended.slice() {
+		add(span)
+	}
+	for _, span := range c.running {
+		add(span)
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].id < spans[j].id
+	})
+	return spans
+}
+
+func (c *ChromeTraceRecorder) events(now time.Time) []chromeTraceEvent {
+	spans := c.spans()
+
+	var ids []string
+	tids := make(map[string]int)
+	for _, span := range spans {
+		if _, ok := tids[span.track]; !ok {
+			tids[span.track] = 0
+			ids = append(ids, span.track)
+		}
+	}
+	sort.Strings(ids)
+
+	events := make([]chromeTraceEvent, 0, 2*len(ids)+len(spans))
+	for i, id := range ids {
+		tids[id] = i + 1
+
+		name := id
+		if name == "" {
+			name = "(no id)"
+		}
+		events = append(events,
+			chromeTraceEvent{Name: "thread_name", Phase: "M", PID: c.pid, TID: i + 1, Args: map[string]interface{}{"name": name}},
+			chromeTraceEvent{Name: "thread_sort_index", Phase: "M", PID: c.pid, TID: i + 1, Args: map[string]interface{}{"sort_index": i}},
+		)
+	}
+
+	for _, span := range spans {
+		tid := tids[span.track]
+
+		end := span.endTime
+		args := make(map[string]interface{}, len(span.attrs)+1)
+		for key, value := range span.attrs {
+			args[key] = fmt.Sprint(value)
+		}
+		if !span.ended {
+			end = now
+			args["in_progress"] = true
+		}
+		dur := microseconds(end.Sub(span.startTime))
+
+		event := chromeTraceEvent{
+			Name:  span.name,
+			Cat:   span.name,
+			Phase: "X",
+			TS:    timestamp(span.startTime),
+			Dur:   &dur,
+			PID:   c.pid,
+			TID:   tid,
+			Args:  args,
+		}
+		switch span.name {
+		case SpanNameRun:
+			event.Name = span.track
+		case SpanNameAttempt:
+			event.Name = fmt.Sprintf("attempt %v", span.attrs["runnable.attempt"])
+		default:
+			event.Cat = "runnable"
+		}
+
+		for _, err := range span.errs {
+			args["error"] = err.Error()
+
+			var p *PanicError
+			if errors.As(err, &p) && span.name == SpanNameAttempt {
+				events = append(events, chromeTraceEvent{
+					Name:  "panic",
+					Cat:   "runnable.panic",
+					Phase: "i",
+					TS:    timestamp(p.Time),
+					PID:   c.pid,
+					TID:   tid,
+					Scope: "t",
+					Args:  map[string]interface{}{"panic": fmt.Sprint(p.Value), "stack": string(p.Stack)},
+				})
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// timestamp returns t in microseconds since the Unix epoch.
+func timestamp(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Microsecond)
+}
+
+func microseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
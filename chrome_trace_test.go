@@ -0,0 +1,171 @@
+package runnable
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChromeTraceRecorder(t *testing.T) {
+	type event struct {
+		Name  string                 `json:"name"`
+		Cat   string                 `json:"cat"`
+		Phase string                 `json:"ph"`
+		TS    float64                `json:"ts"`
+		Dur   float64                `json:"dur"`
+		TID   int                    `json:"tid"`
+		Args  map[string]interface{} `json:"args"`
+	}
+	type trace struct {
+		TraceEvents []event `json:"traceEvents"`
+	}
+
+	decode := func(t *testing.T, data []byte) (map[string]int, []event) {
+		var tr trace
+		require.NoError(t, json.Unmarshal(data, &tr))
+
+		tracks := make(map[string]int)
+		var events []event
+		for _, ev := range tr.TraceEvents {
+			if ev.Phase == "M" {
+				if ev.Name == "thread_name" {
+					tracks[ev.Args["name"].(string)] = ev.TID
+				}
+				continue
+			}
+			events = append(events, ev)
+		}
+		return tracks, events
+	}
+
+	t.Run("group", func(t *testing.T) {
+		recorder := NewChromeTraceRecorder(100)
+		store := NewStatusStore()
+
+		started := make(chan struct{})
+		attempts := 0
+		group := NewGroupWithOptions([]Runnable{
+			New(func(ctx context.Context) error {
+				started <- struct{}{}
+				<-ctx.Done()
+				return nil
+			}, WithStatus("http", store), WithTracer(recorder)),
+			New(func(ctx context.Context) error {
+				attempts++
+				if attempts == 1 {
+					panic("boom")
+				}
+				return nil
+			}, WithPanicReporter(PanicReporterFunc(func(ctx context.Context, p *PanicError) {})),
+				WithRetry(2, ResetNever), WithStatus("worker", store), WithTracer(recorder)),
+		}, WithStatus("api", store), WithTracer(recorder))
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- group.Run(context.Background())
+		}()
+		<-started
+
+		var buf bytes.Buffer
+		_, err := recorder.WriteTo(&buf)
+		require.NoError(t, err)
+
+		_, events := decode(t, buf.Bytes())
+		for _, ev := range events {
+			if ev.Name == "api" {
+				assert.Equal(t, true, ev.Args["in_progress"])
+			}
+		}
+
+		require.NoError(t, group.Stop(context.Background()))
+		require.NoError(t, <-errCh)
+
+		buf.Reset()
+		_, err = recorder.WriteTo(&buf)
+		require.NoError(t, err)
+
+		tracks, events := decode(t, buf.Bytes())
+		assert.Equal(t, map[string]int{"api": 1, "api/http": 2, "api/worker": 3}, tracks)
+
+		byTrack := make(map[int][]string)
+		for _, ev := range events {
+			byTrack[ev.TID] = append(byTrack[ev.TID], ev.Phase+" "+ev.Name)
+			assert.NotContains(t, ev.Args, "in_progress")
+			if ev.Phase == "X" {
+				assert.GreaterOrEqual(t, ev.Dur, float64(0))
+			}
+		}
+		assert.Equal(t, []string{"X api", "X attempt 1"}, byTrack[1])
+		assert.Equal(t, []string{"X api/http", "X attempt 1"}, byTrack[2])
+		assert.Equal(t, []string{"X api/worker", "i panic", "X attempt 1", "X attempt 2"}, byTrack[3])
+
+		for _, ev := range events {
+			if ev.Phase == "i" {
+				assert.Equal(t, "boom", ev.Args["panic"])
+			}
+			if ev.Name == "attempt 1" && ev.TID == 3 {
+				assert.Equal(t, "panic: boom", ev.Args["error"])
+				assert.Equal(t, "panic", ev.Args["runnable.exit_reason"])
+			}
+		}
+	})
+
+	t.Run("write file", func(t *testing.T) {
+		recorder := NewChromeTraceRecorder(100)
+
+		r := New(func(ctx context.Context) error {
+			_, span := recorder.StartSpan(ctx, "work", nil)
+			span.End()
+			return nil
+		}, WithTracer(recorder))
+		require.NoError(t, r.Run(context.Background()))
+
+		path := filepath.Join(t.TempDir(), "trace.json")
+		require.NoError(t, recorder.WriteFile(path))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		tracks, events := decode(t, data)
+		assert.Equal(t, map[string]int{"(no id)": 1}, tracks)
+		require.Len(t, events, 3)
+		assert.Equal(t, "work", events[2].Name)
+		assert.Equal(t, "runnable", events[2].Cat)
+
+		recorder.Reset()
+		_, events = decode(t, func() []byte {
+			var buf bytes.Buffer
+			_, err := recorder.WriteTo(&buf)
+			require.NoError(t, err)
+			return buf.Bytes()
+		}())
+		assert.Empty(t, events)
+	})
+	t.Run("max spans", func(t *testing.T) {
+		recorder := NewChromeTraceRecorder(3)
+
+		r := New(func(ctx context.Context) error {
+			return assert.AnError
+		}, WithRetry(10, ResetNever), WithStatus("worker", NewStatusStore()), WithTracer(recorder))
+		require.Error(t, r.Run(context.Background()))
+
+		var buf bytes.Buffer
+		_, err := recorder.WriteTo(&buf)
+		require.NoError(t, err)
+
+		tracks, events := decode(t, buf.Bytes())
+		assert.Equal(t, map[string]int{"worker": 1}, tracks)
+
+		var names []string
+		for _, ev := range events {
+			names = append(names, ev.Name)
+		}
+		assert.Equal(t, []string{"worker", "attempt 9", "attempt 10"}, names)
+	})
+}
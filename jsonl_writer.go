@@ -0,0 +1,67 @@
+package runnable
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// jsonlWriter appends values as lines of JSON to an io.Writer. It is safe for
+// concurrent use. JSONLBackend and AuditLog are built on it.
+type jsonlWriter struct {
+	w   io.Writer
+	err error
+
+	mu sync.Mutex
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	return &jsonlWriter{
+		w: w,
+	}
+}
+
+// write appends v to the writer. A line is written with a single call to Write,
+// so that lines written concurrently are not interleaved.
+func (j *jsonlWriter) write(v interface{}) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		data = append(data, '\n')
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err == nil {
+		_, err = j.w.Write(data)
+	}
+	if err != nil {
+		j.err = err
+	}
+}
+
+// lastErr returns the last error that occurred while writing a value, if any, or
+// the error reported by the Err method of the writer, e.g. the rotation error of
+// a RotatingFile, whose writes still succeed.
+func (j *jsonlWriter) lastErr() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.err == nil {
+		if w, ok := j.w.(interface{ Err() error }); ok {
+			return w.Err()
+		}
+	}
+	return j.err
+}
+
+// close closes the writer, if it is an io.Closer.
+func (j *jsonlWriter) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if closer, ok := j.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
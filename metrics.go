@@ -0,0 +1,176 @@
+package runnable
+
+import (
+	"expvar"
+	"fmt"
+	"math"
+	"sync"
+)
+
+type metricKey struct {
+	name string
+	id   string
+}
+
+// MemoryMetrics is a Metrics that keeps the metrics in memory, for tests.
+type MemoryMetrics struct {
+	counters   map[metricKey]float64
+	gauges     map[metricKey]float64
+	histograms map[metricKey][]float64
+
+	mu sync.Mutex
+}
+
+var _ Metrics = (*MemoryMetrics)(nil)
+
+// NewMemoryMetrics creates a new MemoryMetrics.
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		counters:   make(map[metricKey]float64),
+		gauges:     make(map[metricKey]float64),
+		histograms: make(map[metricKey][]float64),
+	}
+}
+
+func (m *MemoryMetrics) AddCounter(name, id string, delta float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[metricKey{name, id}] += delta
+}
+
+func (m *MemoryMetrics) SetGauge(name, id string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gauges[metricKey{name, id}] = value
+}
+
+func (m *MemoryMetrics) ObserveHistogram(name, id string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := metricKey{name, id}
+	m.histograms[key] = append(m.histograms[key], value)
+}
+
+// Counter returns the value of the counter name of the runnable id.
+func (m *MemoryMetrics) Counter(name, id string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counters[metricKey{name, id}]
+}
+
+// Gauge returns the value of the gauge name of the runnable id.
+func (m *MemoryMetrics) Gauge(name, id string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.gauges[metricKey{name, id}]
+}
+
+// Histogram returns the values observed by the histogram name of the runnable id,
+// in order.
+func (m *MemoryMetrics) Histogram(name, id string) []float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]float64(nil), m.histograms[metricKey{name, id}]...)
+}
+
+// ExpvarMetrics is a Metrics that publishes the metrics with the expvar package,
+// as a map of metric names to maps of runnable IDs to values. Histograms are
+// published as their count, sum, min and max.
+type ExpvarMetrics struct {
+	root *expvar.Map
+
+	mu sync.Mutex
+}
+
+var _ Metrics = (*ExpvarMetrics)(nil)
+
+// NewExpvarMetrics creates a new ExpvarMetrics published under name. Like
+// expvar.NewMap, it panics if name is already published.
+//
+// Example:
+//
+//	metrics := runnable.NewExpvarMetrics("runnables")
+//	r := runnable.New(run, runnable.WithStatus("worker", store), runnable.WithMetrics(metrics))
+//
+//	// the metrics are served by the expvar handler, e.g. on /debug/vars
+//	http.Handle("/debug/vars", expvar.Handler())
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{
+		root: expvar.NewMap(name),
+	}
+}
+
+func (e *ExpvarMetrics) AddCounter(name, id string, delta float64) {
+	e.float(name, id).Add(delta)
+}
+
+func (e *ExpvarMetrics) SetGauge(name, id string, value float64) {
+	e.float(name, id).Set(value)
+}
+
+func (e *ExpvarMetrics) ObserveHistogram(name, id string, value float64) {
+	e.mu.Lock()
+	metric := e.metric(name)
+	histogram, ok := metric.Get(id).(*expvarHistogram)
+	if !ok {
+		histogram = &expvarHistogram{}
+		metric.Set(id, histogram)
+	}
+	e.mu.Unlock()
+
+	histogram.observe(value)
+}
+
+func (e *ExpvarMetrics) float(name, id string) *expvar.Float {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	metric := e.metric(name)
+	f, ok := metric.Get(id).(*expvar.Float)
+	if !ok {
+		f = new(expvar.Float)
+		metric.Set(id, f)
+	}
+	return f
+}
+
+// metric returns the map of the metric name, creating it if needed. e.mu must be locked.
+func (e *ExpvarMetrics) metric(name string) *expvar.Map {
+	metric, ok := e.root.Get(name).(*expvar.Map)
+	if !ok {
+		metric = new(expvar.Map).Init()
+		e.root.Set(name, metric)
+	}
+	return metric
+}
+
+type expvarHistogram struct {
+	count int64
+	sum   float64
+	min   float64
+	max   float64
+
+	mu sync.Mutex
+}
+
+func (h *expvarHistogram) observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.count == 0 {
+		h.min, h.max = value, value
+	} else {
+		h.min = math.Min(h.min, value)
+		h.max = math.Max(h.max, value)
+	}
+	h.count++
+	h.sum += value
+}
+
+// String returns the histogram as JSON. It implements expvar.Var.
+func (h *expvarHistogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return fmt.Sprintf(`{"count":%d,"sum":%g,"min":%g,"max":%g}`, h.count, h.sum, h.min, h.max)
+}
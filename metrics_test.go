@@ -0,0 +1,51 @@
+package runnable
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryMetrics(t *testing.T) {
+	metrics := NewMemoryMetrics()
+
+	metrics.AddCounter("counter", "a", 1)
+	metrics.AddCounter("counter", "a", 2)
+	metrics.SetGauge("gauge", "a", 5)
+	metrics.SetGauge("gauge", "a", 3)
+	metrics.ObserveHistogram("histogram", "a", 1)
+	metrics.ObserveHistogram("histogram", "a", 2)
+
+	assert.Equal(t, float64(3), metrics.Counter("counter", "a"))
+	assert.Equal(t, float64(0), metrics.Counter("counter", "b"))
+	assert.Equal(t, float64(3), metrics.Gauge("gauge", "a"))
+	assert.Equal(t, []float64{1, 2}, metrics.Histogram("histogram", "a"))
+	assert.Empty(t, metrics.Histogram("histogram", "b"))
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	name := fmt.Sprintf("runnable_test_metrics_%d", time.Now().UnixNano())
+	metrics := NewExpvarMetrics(name)
+
+	metrics.AddCounter(MetricRuns, "a", 1)
+	metrics.AddCounter(MetricRuns, "a", 2)
+	metrics.SetGauge(MetricRunning, "a", 1)
+	metrics.ObserveHistogram(MetricRunDuration, "a", 0.5)
+	metrics.ObserveHistogram(MetricRunDuration, "a", 1.5)
+	metrics.ObserveHistogram(MetricRunDuration, "b", 2)
+
+	var vars map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &vars))
+
+	assert.Equal(t, map[string]interface{}{"a": float64(3)}, vars[MetricRuns])
+	assert.Equal(t, map[string]interface{}{"a": float64(1)}, vars[MetricRunning])
+	assert.Equal(t, map[string]interface{}{
+		"a": map[string]interface{}{"count": float64(2), "sum": float64(2), "min": 0.5, "max": 1.5},
+		"b": map[string]interface{}{"count": float64(1), "sum": float64(2), "min": float64(2), "max": float64(2)},
+	}, vars[MetricRunDuration])
+}
@@ -0,0 +1,126 @@
+package runnable
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RecordingTracer is a Tracer that keeps the spans it creates in memory, for tests.
+type RecordingTracer struct {
+	spans []*recordingSpan
+
+	mu sync.Mutex
+}
+
+var _ Tracer = (*RecordingTracer)(nil)
+
+// RecordedSpan is a span recorded by a RecordingTracer. IDs start at 1; ParentID
+// is zero for root spans.
+type RecordedSpan struct {
+	ID         int
+	ParentID   int
+	Name       string
+	Attributes map[string]interface{}
+	Errors     []error
+	StartTime  time.Time
+	EndTime    time.Time
+	Ended      bool
+}
+
+type recordingSpan struct {
+	tracer *RecordingTracer
+	span   RecordedSpan
+}
+
+type recordingSpanKey struct{}
+
+// NewRecordingTracer creates a new RecordingTracer.
+//
+// Example:
+//
+//	tracer := runnable.NewRecordingTracer()
+//	r := runnable.New(run, runnable.WithTracer(tracer))
+//	_ = r.Run(ctx)
+//
+//	for _, span := range tracer.Spans() {
+//		fmt.Println(span.Name, span.Attributes)
+//	}
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+func (t *RecordingTracer) StartSpan(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, Span) {
+	span := &recordingSpan{
+		tracer: t,
+		span: RecordedSpan{
+			Name:       name,
+			Attributes: make(map[string]interface{}, len(attrs)),
+			StartTime:  time.Now(),
+		},
+	}
+	for key, value := range attrs {
+		span.span.Attributes[key] = value
+	}
+	if parent, ok := ctx.Value(recordingSpanKey{}).(*recordingSpan); ok && parent.tracer == t {
+		span.span.ParentID = parent.span.ID
+	}
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	span.span.ID = len(t.spans)
+	t.mu.Unlock()
+
+	return context.WithValue(ctx, recordingSpanKey{}, span), span
+}
+
+// Spans returns a copy of the spans recorded so far, in the order they started.
+func (t *RecordingTracer) Spans() []RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spans := make([]RecordedSpan, 0, len(t.spans))
+	for _, span := range t.spans {
+		rs := span.span
+		rs.Attributes = make(map[string]interface{}, len(span.span.Attributes))
+		for key, value := range span.span.Attributes {
+			rs.Attributes[key] = value
+		}
+		rs.Errors = append([]error(nil), span.span.Errors...)
+		spans = append(spans, rs)
+	}
+	return spans
+}
+
+// Reset discards the spans recorded so far.
+func (t *RecordingTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = nil
+}
+
+func (s *recordingSpan) SetAttributes(attrs map[string]interface{}) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	for key, value := range attrs {
+		s.span.Attributes[key] = value
+	}
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.span.Errors = append(s.span.Errors, err)
+}
+
+func (s *recordingSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	if !s.span.Ended {
+		s.span.Ended = true
+		s.span.EndTime = time.Now()
+	}
+}
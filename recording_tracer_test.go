@@ -0,0 +1,45 @@
+package runnable
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingTracer(t *testing.T) {
+	tracer := NewRecordingTracer()
+
+	ctx, root := tracer.StartSpan(context.Background(), "root", map[string]interface{}{"key": "value"})
+	_, child := tracer.StartSpan(ctx, "child", nil)
+	child.SetAttributes(map[string]interface{}{"count": 1})
+	child.RecordError(assert.AnError)
+	child.End()
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, 1, spans[0].ID)
+	assert.Equal(t, 0, spans[0].ParentID)
+	assert.Equal(t, "value", spans[0].Attributes["key"])
+	assert.False(t, spans[0].Ended)
+
+	assert.Equal(t, 2, spans[1].ID)
+	assert.Equal(t, 1, spans[1].ParentID)
+	assert.Equal(t, 1, spans[1].Attributes["count"])
+	assert.Equal(t, []error{assert.AnError}, spans[1].Errors)
+	assert.True(t, spans[1].Ended)
+	assert.False(t, spans[1].EndTime.Before(spans[1].StartTime))
+
+	spans[1].Attributes["count"] = 2
+	assert.Equal(t, 1, tracer.Spans()[1].Attributes["count"])
+
+	root.End()
+	assert.True(t, tracer.Spans()[0].Ended)
+
+	_, other := NewRecordingTracer().StartSpan(ctx, "other", nil)
+	other.End()
+
+	tracer.Reset()
+	assert.Empty(t, tracer.Spans())
+}
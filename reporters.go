@@ -0,0 +1,176 @@
+package runnable
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SlogReporter is a RecoveryReporter, StackPrinter and PanicReporter that logs
+// panics with a slog.Logger. The stack is logged as the "stack" attribute.
+type SlogReporter struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+var (
+	_ RecoveryReporter = (*SlogReporter)(nil)
+	_ StackPrinter     = (*SlogReporter)(nil)
+	_ PanicReporter    = (*SlogReporter)(nil)
+)
+
+// NewSlogReporter creates a new SlogReporter that logs panics at error level with
+// the given logger. If logger is nil, slog.Default() is used.
+//
+// Example:
+//
+//	reporter := runnable.NewSlogReporter(slog.Default())
+//	r := runnable.New(run, runnable.WithPanicReporter(reporter))
+func NewSlogReporter(logger *slog.Logger) *SlogReporter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &SlogReporter{
+		logger: logger,
+		level:  slog.LevelError,
+	}
+}
+
+func (s *SlogReporter) Report(ctx context.Context, rec interface{}) {
+	s.logger.Log(ctx, s.level, "runnable: panic recovered", slog.Any("panic", rec))
+}
+
+func (s *SlogReporter) Print(ctx context.Context, callstack []byte) {
+	s.logger.Log(ctx, s.level, "runnable: panic stack", slog.String("stack", string(callstack)))
+}
+
+func (s *SlogReporter) ReportPanic(ctx context.Context, p *PanicError) {
+	s.logger.Log(ctx, s.level, "runnable: panic recovered",
+		slog.String("runnable_id", p.RunnableID),
+		slog.Any("panic", p.Value),
+		slog.Time("time", p.Time),
+		slog.String("stack", string(p.Stack)),
+	)
+}
+
+// WriterReporter is a RecoveryReporter, StackPrinter and PanicReporter that writes
+// panics as plain text to an io.Writer, such as os.Stderr or a RotatingFile. It is
+// safe for concurrent use.
+type WriterReporter struct {
+	w  io.Writer
+	mu sync.Mutex
+}
+
+var (
+	_ RecoveryReporter = (*WriterReporter)(nil)
+	_ StackPrinter     = (*WriterReporter)(nil)
+	_ PanicReporter    = (*WriterReporter)(nil)
+	_ Flusher          = (*WriterReporter)(nil)
+)
+
+// NewWriterReporter creates a new WriterReporter that writes to w.
+//
+// Example:
+//
+//	file, err := runnable.NewRotatingFile("/var/log/app/panics.log", 10<<20, 5)
+//	if err != nil {
+//		return err
+//	}
+//	defer file.Close()
+//
+//	r := runnable.New(run, runnable.WithPanicReporter(runnable.NewWriterReporter(file)))
+func NewWriterReporter(w io.Writer) *WriterReporter {
+	return &WriterReporter{
+		w: w,
+	}
+}
+
+func (wr *WriterReporter) Report(ctx context.Context, rec interface{}) {
+	wr.write(fmt.Sprintf("%s panic: %v\n", time.Now().Format(time.RFC3339Nano), rec))
+}
+
+func (wr *WriterReporter) Print(ctx context.Context, callstack []byte) {
+	wr.write(string(callstack) + "\n")
+}
+
+func (wr *WriterReporter) ReportPanic(ctx context.Context, p *PanicError) {
+	if p.RunnableID != "" {
+		wr.write(fmt.Sprintf("%s panic in %q: %v\n%s\n", p.Time.Format(time.RFC3339Nano), p.RunnableID, p.Value, p.Stack))
+	} else {
+		wr.write(fmt.Sprintf("%s panic: %v\n%s\n", p.Time.Format(time.RFC3339Nano), p.Value, p.Stack))
+	}
+}
+
+// Flush flushes the underlying writer if it is buffered, or syncs it to stable
+// storage if it is a file.
+func (wr *WriterReporter) Flush() error {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	switch w := wr.w.(type) {
+	case Flusher:
+		return w.Flush()
+	case interface{ Sync() error }:
+		return w.Sync()
+	}
+	return nil
+}
+
+func (wr *WriterReporter) write(s string) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+	_, _ = io.WriteString(wr.w, s)
+}
+
+// RingReporter is a PanicReporter that keeps the last panics in memory, so they
+// can be served by debugging endpoints. It is safe for concurrent use.
+type RingReporter struct {
+	panics *ring[PanicError]
+	mu     sync.Mutex
+}
+
+var _ PanicReporter = (*RingReporter)(nil)
+
+// NewRingReporter creates a new RingReporter that keeps up to size panics.
+func NewRingReporter(size int) *RingReporter {
+	return &RingReporter{
+		panics: newRing[PanicError](size),
+	}
+}
+
+func (rr *RingReporter) ReportPanic(ctx context.Context, p *PanicError) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.panics.push(*p)
+}
+
+// Last returns up to n of the most recent panics, most recent first. If n is
+// less than or equal to zero, all the retained panics are returned.
+func (rr *RingReporter) Last(n int) []PanicError {
+	rr.mu.Lock()
+	panics := rr.panics.slice()
+	rr.mu.Unlock()
+
+	if n <= 0 || n > len(panics) {
+		n = len(panics)
+	}
+
+	last := make([]PanicError, 0, n)
+	for i := len(panics) - 1; i >= len(panics)-n; i-- {
+		last = append(last, panics[i])
+	}
+	return last
+}
+
+// Len returns the number of retained panics.
+func (rr *RingReporter) Len() int {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	return rr.panics.len()
+}
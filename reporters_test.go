@@ -0,0 +1,88 @@
+package runnable
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporters(t *testing.T) {
+
+	t.Run("slog reporter", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		reporter := NewSlogReporter(slog.New(slog.NewJSONHandler(buf, nil)))
+
+		r := New(func(ctx context.Context) error {
+			panic("something went wrong")
+		}, WithStatus("test", NewStatusStore()), WithPanicReporter(reporter))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "ERROR", record["level"])
+		assert.Equal(t, "test", record["runnable_id"])
+		assert.Equal(t, "something went wrong", record["panic"])
+		assert.Contains(t, record["stack"], "runtime/debug.Stack")
+	})
+
+	t.Run("slog reporter, legacy interfaces", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		reporter := NewSlogReporter(slog.New(slog.NewTextHandler(buf, nil)))
+
+		r := New(func(ctx context.Context) error {
+			panic("something went wrong")
+		}, WithRecoverer(reporter, reporter))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, buf.String(), "stack=")
+		assert.Contains(t, buf.String(), `panic="something went wrong"`)
+	})
+
+	t.Run("writer reporter", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		r := New(func(ctx context.Context) error {
+			panic("something went wrong")
+		}, WithStatus("test", NewStatusStore()), WithPanicReporter(NewWriterReporter(buf)))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, buf.String(), `panic in "test": something went wrong`)
+		assert.Contains(t, buf.String(), "runtime/debug.Stack")
+	})
+
+	t.Run("ring reporter", func(t *testing.T) {
+		reporter := NewRingReporter(3)
+		assert.Empty(t, reporter.Last(10))
+
+		for i := 0; i < 5; i++ {
+			i := i
+			r := New(func(ctx context.Context) error {
+				panic(fmt.Sprintf("panic %d", i))
+			}, WithPanicReporter(reporter))
+			require.Error(t, r.Run(context.Background()))
+		}
+
+		assert.Equal(t, 3, reporter.Len())
+
+		last := reporter.Last(2)
+		require.Len(t, last, 2)
+		assert.Equal(t, "panic 4", last[0].Value)
+		assert.Equal(t, "panic 3", last[1].Value)
+
+		all := reporter.Last(0)
+		require.Len(t, all, 3)
+		assert.Equal(t, "panic 2", all[2].Value)
+		assert.True(t, strings.Contains(string(all[2].Stack), "runtime/debug.Stack"))
+	})
+}
@@ -0,0 +1,42 @@
+package runnable
+
+// ring is a fixed size circular buffer that keeps the last items pushed to it.
+type ring[T any] struct {
+	items []T
+	next  int
+	full  bool
+}
+
+func newRing[T any](size int) *ring[T] {
+	if size < 1 {
+		size = 1
+	}
+
+	return &ring[T]{
+		items: make([]T, size),
+	}
+}
+
+func (r *ring[T]) push(item T) {
+	r.items[r.next] = item
+	r.next = (r.next + 1) % len(r.items)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *ring[T]) len() int {
+	if r.full {
+		return len(r.items)
+	}
+	return r.next
+}
+
+// slice returns a copy of the items, oldest first.
+func (r *ring[T]) slice() []T {
+	items := make([]T, 0, r.len())
+	if r.full {
+		items = append(items, r.items[r.next:]...)
+	}
+	return append(items, r.items[:r.next]...)
+}
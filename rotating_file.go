@@ -0,0 +1,168 @@
+package runnable
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an io.WriteCloser that appends to a file and rotates it once it
+// grows beyond a maximum size. Rotated files are renamed to path.1, path.2, ...,
+// path.N, the highest number being the oldest; files beyond maxBackups are removed.
+// It is safe for concurrent use.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file   *os.File
+	size   int64
+	closed bool
+	// rotateErr is the error of the last rotation, if it failed
+	rotateErr error
+
+	mu sync.Mutex
+}
+
+var _ io.WriteCloser = (*RotatingFile)(nil)
+
+// NewRotatingFile opens, or creates, the file at path for appending. The file is
+// rotated when a write would make it grow beyond maxSize bytes; a maxSize of zero
+// disables rotation.
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// Write appends p to the file, rotating it first if needed. If the rotation fails,
+// p is still appended to the file, which grows beyond maxSize, the error is kept
+// for Err and the rotation is retried on the next write.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if err := rf.ensureOpen(); err != nil {
+		return 0, err
+	}
+
+	if rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		rf.rotateErr = rf.rotate()
+		if rf.file == nil {
+			return 0, rf.rotateErr
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Sync commits the current contents of the file to stable storage.
+func (rf *RotatingFile) Sync() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if err := rf.ensureOpen(); err != nil {
+		return err
+	}
+	return rf.file.Sync()
+}
+
+// Err returns the error of the last rotation, if it failed.
+func (rf *RotatingFile) Err() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.rotateErr
+}
+
+// Close closes the file.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return os.ErrClosed
+	}
+	rf.closed = true
+
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
+
+// ensureOpen reopens the file if a previous rotation could not.
+func (rf *RotatingFile) ensureOpen() error {
+	if rf.closed {
+		return os.ErrClosed
+	}
+	if rf.file == nil {
+		return rf.open()
+	}
+	return nil
+}
+
+func (rf *RotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	rf.file = file
+	rf.size = info.Size()
+	return nil
+}
+
+// rotate rotates the file. The file at path is reopened even if the rotation
+// fails, so that the next writes are not lost.
+func (rf *RotatingFile) rotate() error {
+	err := rf.file.Close()
+	rf.file = nil
+	if err == nil {
+		err = rf.shift()
+	}
+
+	if openErr := rf.open(); err == nil {
+		err = openErr
+	}
+	return err
+}
+
+// shift renames the file and its backups, removing the oldest one.
+func (rf *RotatingFile) shift() error {
+	if rf.maxBackups > 0 {
+		for i := rf.maxBackups - 1; i > 0; i-- {
+			err := os.Rename(rf.backupPath(i), rf.backupPath(i+1))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(rf.path, rf.backupPath(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(rf.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (rf *RotatingFile) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", rf.path, i)
+}
@@ -0,0 +1,97 @@
+package runnable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+
+	t.Run("rotate by size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "panics.log")
+
+		rf, err := NewRotatingFile(path, 10, 2)
+		require.NoError(t, err)
+
+		for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+			_, err := rf.Write([]byte(line))
+			require.NoError(t, err)
+		}
+		require.NoError(t, rf.Close())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "dddddddd\n", string(data))
+
+		data, err = os.ReadFile(path + ".1")
+		require.NoError(t, err)
+		assert.Equal(t, "cccccccc\n", string(data))
+
+		data, err = os.ReadFile(path + ".2")
+		require.NoError(t, err)
+		assert.Equal(t, "bbbbbbbb\n", string(data))
+
+		_, err = os.Stat(path + ".3")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("append to existing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "panics.log")
+		require.NoError(t, os.WriteFile(path, []byte("aaaaaaaa\n"), 0o644))
+
+		rf, err := NewRotatingFile(path, 10, 1)
+		require.NoError(t, err)
+
+		_, err = rf.Write([]byte("bbbbbbbb\n"))
+		require.NoError(t, err)
+		require.NoError(t, rf.Close())
+
+		data, err := os.ReadFile(path + ".1")
+		require.NoError(t, err)
+		assert.Equal(t, "aaaaaaaa\n", string(data))
+
+		_, err = rf.Write([]byte("cccccccc\n"))
+		require.ErrorIs(t, err, os.ErrClosed)
+	})
+
+	t.Run("rotation failure", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "panics.log")
+
+		rf, err := NewRotatingFile(path, 10, 1)
+		require.NoError(t, err)
+		defer rf.Close()
+
+		_, err = rf.Write([]byte("aaaaaaaa\n"))
+		require.NoError(t, err)
+
+		// path.1 is a non-empty directory, so path cannot be renamed to it
+		require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "dir"), 0o755))
+
+		// the write is not lost
+		_, err = rf.Write([]byte("bbbbbbbb\n"))
+		require.NoError(t, err)
+		assert.Error(t, rf.Err())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "aaaaaaaa\nbbbbbbbb\n", string(data))
+
+		require.NoError(t, os.RemoveAll(path+".1"))
+
+		_, err = rf.Write([]byte("cccccccc\n"))
+		require.NoError(t, err)
+		assert.NoError(t, rf.Err())
+
+		data, err = os.ReadFile(path + ".1")
+		require.NoError(t, err)
+		assert.Equal(t, "aaaaaaaa\nbbbbbbbb\n", string(data))
+
+		data, err = os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "cccccccc\n", string(data))
+	})
+}
@@ -3,7 +3,9 @@ package runnable
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"sync"
+	"time"
 )
 
 var (
@@ -11,26 +13,87 @@ var (
 	ErrNotRunning     = fmt.Errorf("not running")
 )
 
+// errStopCalled is the cause of the stop requests made by Stop.
+var errStopCalled = fmt.Errorf("stop called")
+
 type Option interface {
 	apply(*runnable)
 }
 
+// optionFunc is an Option that calls the function with each runnable it is
+// applied to. Options that keep state for their runnable create it in the
+// function, as the same option may be applied to several runnables.
+type optionFunc func(r *runnable)
+
+func (f optionFunc) apply(r *runnable) {
+	f(r)
+}
+
 type Runnable interface {
 	Run(ctx context.Context) error
 	Stop(ctx context.Context) error
 	IsRunning() bool
 }
 
+// runHook is implemented by options that need to act around a whole run, retries included.
+type runHook interface {
+	// beforeRun is called by Run before onStart. It may derive the run context, or
+	// abort the run by returning an error.
+	beforeRun(ctx context.Context) (context.Context, error)
+	// afterRun is called by Run after onStop, if beforeRun succeeded, with the error
+	// returned by Run.
+	afterRun(ctx context.Context, err error)
+}
+
+// attemptHook is implemented by run hooks that need to act on the context of each
+// attempt of a run.
+type attemptHook interface {
+	// beforeAttempt is called by start, before onStart, with the context of the
+	// attempt. It may derive it, e.g. to hold a span.
+	beforeAttempt(ctx context.Context) context.Context
+}
+
 type runnable struct {
-	runFunc func(ctx context.Context) error
+	// id is the ID of the runnable, nested under the ID of its group for the
+	// current run (see WithStatus). It is resolved by Run, before the run hooks
+	// are called, from statusID, the ID given to WithStatus.
+	id       string
+	statusID string
+	runFunc  func(ctx context.Context) error
 
 	runCtx    context.Context
 	runCancel context.CancelFunc
 	runStop   chan bool
 
 	isRunning bool
-	onStart   func()
-	onStop    func()
+	// stopRequested is the first request to stop the current run, by Stop or by
+	// the cancellation of the context given to Run.
+	stopRequested *stopRequest
+
+	onStart         func()
+	onStop          func(err error)
+	onStopRequested func(req stopRequest)
+
+	// runScope is the context of the current run, as derived by the run hooks; the
+	// context of each attempt is derived from it. It and the fields below are only
+	// accessed by the goroutine that runs runFunc.
+	runScope context.Context
+	// attempting is true between start and stop.
+	attempting bool
+	// attempt is the number of the current, or last, attempt of the run, starting
+	// at 1. runStart and attemptStart are the times the run and the attempt started.
+	attempt      int
+	runStart     time.Time
+	attemptStart time.Time
+	// children tracks the goroutines spawned with Go during the current attempt.
+	children *children
+
+	runHooks []runHook
+
+	restartLimiter *RestartLimiter
+	panicReporters []PanicReporter
+	recoveryMode   RecoveryMode
+	optional       bool
 
 	mu sync.Mutex
 }
@@ -76,7 +139,7 @@ func New(runFunc func(ctx context.Context) error, options ...Option) Runnable {
 //	if err := runnable.Run(ctx); err != nil {
 //		log.Error(err)
 //	}
-func (r *runnable) Run(ctx context.Context) error {
+func (r *runnable) Run(ctx context.Context) (err error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -90,30 +153,62 @@ func (r *runnable) Run(ctx context.Context) error {
 	r.isRunning = true
 	r.runCtx, r.runCancel = context.WithCancel(ctx)
 	r.runStop = make(chan bool)
+	r.stopRequested = nil
+	if r.statusID != "" {
+		r.id, _ = statusKey(ctx, r.statusID)
+	}
 
 	runCtx := r.runCtx
 	r.mu.Unlock()
 
 	defer func() {
-		if r.onStop != nil {
-			r.onStop()
-		}
-
 		r.mu.Lock()
 		r.isRunning = false
 		close(r.runStop)
 		r.mu.Unlock()
 	}()
 
-	if r.onStart != nil {
-		r.onStart()
+	r.attempt, r.runStart = 0, time.Now()
+	runCtx, err = r.beforeRun(runCtx)
+	if err != nil {
+		r.runCancel()
+		return err
 	}
-	return r.runFunc(runCtx)
+	defer func() {
+		r.afterRun(runCtx, len(r.runHooks), err)
+	}()
+	r.runScope = runCtx
+
+	returned := false
+	defer func() {
+		if returned {
+			err = r.stop(err)
+		} else {
+			r.stop(errUnrecoveredPanic)
+		}
+	}()
+
+	parentDone := make(chan struct{})
+	stopAfterParent := context.AfterFunc(ctx, func() {
+		r.requestStop(context.Cause(ctx), "")
+		close(parentDone)
+	})
+	defer func() {
+		// a stop requested by the cancellation of ctx is recorded before onStop is called
+		if !stopAfterParent() {
+			<-parentDone
+		}
+	}()
+
+	err = r.runFunc(r.start())
+	returned = true
+	return err
 }
 
 // Stop stops the runnable, if it is running. If the context is cancelled, it will return the context error.
 // If the runnable is not running, it will return an error.
-// If the runnable is running, it will wait for the runnable to stop before returning.
+// If the runnable is running, it will wait for the runnable, and the goroutines it spawned with Go, to stop
+// before returning.
 //
 // Example:
 //
@@ -136,6 +231,8 @@ func (r *runnable) Stop(ctx context.Context) error {
 	runStop := r.runStop
 	r.mu.Unlock()
 
+	r.requestStop(errStopCalled, callerOf(1))
+
 	r.runCancel()
 
 	select {
@@ -152,3 +249,171 @@ func (r *runnable) IsRunning() bool {
 	defer r.mu.Unlock()
 	return r.isRunning
 }
+
+// stopRequest describes why a run was asked to stop.
+type stopRequest struct {
+	time time.Time
+	// id is the ID of the runnable for the run, see runnable.id.
+	id string
+	// cause is errStopCalled, or the cause of the cancellation of the context given to Run.
+	cause error
+	// caller is the function and position of the caller of Stop, if any.
+	caller string
+}
+
+// requestStop records that the current run was asked to stop, if it was not
+// already, and calls the onStopRequested hook, if any.
+func (r *runnable) requestStop(cause error, caller string) {
+	r.mu.Lock()
+	if !r.isRunning || r.stopRequested != nil {
+		r.mu.Unlock()
+		return
+	}
+
+	req := stopRequest{
+		time:   time.Now(),
+		id:     r.id,
+		cause:  cause,
+		caller: caller,
+	}
+	r.stopRequested = &req
+	r.mu.Unlock()
+
+	if r.onStopRequested != nil {
+		r.safeCall(context.Background(), func() { r.onStopRequested(req) })
+	}
+}
+
+// stopRequest returns the first request to stop the current run, and false if
+// there was none.
+func (r *runnable) stopRequest() (stopRequest, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopRequested == nil {
+		return stopRequest{}, false
+	}
+	return *r.stopRequested, true
+}
+
+// callerOf returns the function and position of the caller of the function that
+// calls callerOf, skip frames up. Autogenerated frames, e.g. of methods promoted
+// from an embedded Runnable, are skipped.
+func callerOf(skip int) string {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(skip+2, pcs)
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.File != "<autogenerated>" && frame.Function != "" {
+			return fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// start starts an attempt of the run, calling the beforeAttempt method of the run
+// hooks and the onStart hook, if any, and returns the context of the attempt. Run starts the first attempt, and WithRetry
+// the next ones. Panics raised by the hook are recovered and reported.
+func (r *runnable) start() context.Context {
+	ctx, cancel := context.WithCancel(r.runScope)
+	r.children = &children{r: r, parent: r.runScope, cancel: cancel}
+	ctx = context.WithValue(ctx, childrenKey{}, r.children)
+
+	r.attempt++
+	r.attemptStart = time.Now()
+	for _, hook := range r.runHooks {
+		if hook, ok := hook.(attemptHook); ok {
+			// ctx is kept as is if the hook panics
+			r.safeCall(ctx, func() { ctx = hook.beforeAttempt(ctx) })
+		}
+	}
+
+	r.attempting = true
+	if r.onStart != nil {
+		r.safeCall(ctx, r.onStart)
+	}
+	return ctx
+}
+
+// stop ends the current attempt of the run, if any: it waits for the goroutines
+// spawned with Go during the attempt, and calls the onStop hook, if any, with the
+// error of the attempt, joined with theirs. It returns that error. Panics raised by
+// the hook are recovered and reported.
+func (r *runnable) stop(err error) error {
+	if !r.attempting {
+		return err
+	}
+	r.attempting = false
+	err = r.children.wait(err)
+	if r.onStop != nil {
+		r.safeCall(r.runScope, func() { r.onStop(err) })
+	}
+	return err
+}
+
+// chainHooks adds onStart and onStop, either of which may be nil, to the hooks of
+// the runnable. They are called before the hooks added by the options applied
+// earlier. Each hook is called with safeCall, so that a panicking hook does not
+// prevent the next ones from being called.
+func (r *runnable) chainHooks(onStart func(), onStop func(err error)) {
+	if next := r.onStart; onStart != nil {
+		r.onStart = func() {
+			r.safeCall(r.runScope, onStart)
+			if next != nil {
+				r.safeCall(r.runScope, next)
+			}
+		}
+	}
+
+	if next := r.onStop; onStop != nil {
+		r.onStop = func(err error) {
+			r.safeCall(r.runScope, func() { onStop(err) })
+			if next != nil {
+				r.safeCall(r.runScope, func() { next(err) })
+			}
+		}
+	}
+}
+
+// chainStopRequested adds onStopRequested to the hooks of the runnable, like chainHooks.
+func (r *runnable) chainStopRequested(onStopRequested func(req stopRequest)) {
+	next := r.onStopRequested
+	r.onStopRequested = func(req stopRequest) {
+		r.safeCall(context.Background(), func() { onStopRequested(req) })
+		if next != nil {
+			r.safeCall(context.Background(), func() { next(req) })
+		}
+	}
+}
+
+// beforeRun calls the beforeRun method of the run hooks in order. If one of them
+// fails or panics, the hooks already called are unwound and the error is returned.
+func (r *runnable) beforeRun(ctx context.Context) (context.Context, error) {
+	for i, hook := range r.runHooks {
+		var (
+			hookCtx context.Context
+			err     error
+		)
+		if p := r.safeCall(ctx, func() { hookCtx, err = hook.beforeRun(ctx) }); p != nil {
+			err = p
+		}
+		if err != nil {
+			r.afterRun(ctx, i, err)
+			return ctx, err
+		}
+		ctx = hookCtx
+	}
+	return ctx, nil
+}
+
+// afterRun calls the afterRun method of the first n run hooks in reverse order.
+func (r *runnable) afterRun(ctx context.Context, n int, err error) {
+	for i := n - 1; i >= 0; i-- {
+		hook := r.runHooks[i]
+		r.safeCall(ctx, func() { hook.afterRun(ctx, err) })
+	}
+}
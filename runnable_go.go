@@ -0,0 +1,142 @@
+package runnable
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+	"sync"
+)
+
+type childrenKey struct{}
+
+// children tracks the goroutines spawned with Go during a single attempt of a run
+// of a runnable.
+type children struct {
+	r *runnable
+	// parent is the context of the run, and cancel cancels the context of the attempt
+	parent context.Context
+	cancel context.CancelFunc
+
+	wg     sync.WaitGroup
+	err    error
+	closed bool
+
+	mu sync.Mutex
+}
+
+// Go runs fn in a new goroutine tied to the lifetime of the attempt of the
+// runnable whose context ctx is derived from:
+//
+//   - a panic in fn is recovered, reported to the panic reporters of the runnable
+//     (see WithRecoverer and WithPanicReporter) and treated as an error,
+//   - the first error returned by fn cancels the context of the attempt and is the
+//     error of the attempt, along with the error of runFunc,
+//   - once runFunc returns, the context of the attempt is cancelled and Run, and
+//     therefore Stop, waits for all the goroutines to return.
+//
+// With WithRetry, each attempt has its own goroutines: those of a failed attempt
+// return before the next attempt starts, and a failed goroutine makes its attempt
+// fail and be retried.
+//
+// If ctx is not derived from a run context, fn runs in a plain goroutine, its panics
+// are recovered and logged, and its error is discarded. If the runnable has already
+// finished, fn is not run.
+//
+// Example:
+//
+//	r := runnable.New(func(ctx context.Context) error {
+//		runnable.Go(ctx, func(ctx context.Context) error {
+//			return consume(ctx)
+//		})
+//		return produce(ctx)
+//	}, runnable.WithRecoverer(reporter, nil))
+func Go(ctx context.Context, fn func(ctx context.Context) error) {
+	c, ok := ctx.Value(childrenKey{}).(*children)
+	if !ok {
+		go func() {
+			defer func() {
+				if recovery := recover(); recovery != nil {
+					log.Printf("runnable: panic in goroutine: %v\n%s", recovery, debug.Stack())
+				}
+			}()
+			_ = fn(ctx)
+		}()
+		return
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.wg.Add(1)
+	c.mu.Unlock()
+
+	go func() {
+		defer c.wg.Done()
+		c.setErr(ctx, c.run(ctx, fn))
+	}()
+}
+
+func (c *children) run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if recovery := recover(); recovery != nil {
+			p := c.r.newPanicError(recovery, debug.Stack())
+			c.r.handlePanic(ctx, p)
+			err = p
+		}
+	}()
+
+	return fn(ctx)
+}
+
+func (c *children) setErr(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	// errors caused by the run context being cancelled are not child failures
+	if ctx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+		c.cancel()
+	}
+}
+
+// wait cancels the context of the attempt, waits for all the goroutines to return
+// and joins the first child error, if any, with err. If err is the cancellation of
+// the attempt by the child error, the child error is returned alone. Once the
+// goroutines were waited for, wait returns err as is.
+func (c *children) wait(err error) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return err
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	c.cancel()
+	c.wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		return err
+	}
+	if err == nil || errors.Is(err, c.err) {
+		return c.err
+	}
+	if c.parent.Err() == nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return c.err
+	}
+	return errors.Join(err, c.err)
+}
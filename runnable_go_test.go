@@ -0,0 +1,176 @@
+package runnable
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGo(t *testing.T) {
+
+	t.Run("stop waits for children", func(t *testing.T) {
+		started := make(chan struct{})
+		var stopped int32
+
+		r := New(func(ctx context.Context) error {
+			for i := 0; i < 3; i++ {
+				Go(ctx, func(ctx context.Context) error {
+					<-ctx.Done()
+					time.Sleep(100 * time.Millisecond)
+					atomic.AddInt32(&stopped, 1)
+					return ctx.Err()
+				})
+			}
+			started <- struct{}{}
+
+			<-ctx.Done()
+			return nil
+		})
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- r.Run(context.Background())
+		}()
+
+		<-started
+
+		err := r.Stop(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&stopped))
+		assert.Equal(t, false, r.IsRunning())
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("child error propagates", func(t *testing.T) {
+		r := New(func(ctx context.Context) error {
+			Go(ctx, func(ctx context.Context) error {
+				return assert.AnError
+			})
+
+			<-ctx.Done()
+			return nil
+		})
+
+		err := r.Run(context.Background())
+		require.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("child panic is recovered and reported", func(t *testing.T) {
+		reporter := &InMemoryReporter{}
+
+		r := New(func(ctx context.Context) error {
+			Go(ctx, func(ctx context.Context) error {
+				panic("something went wrong")
+			})
+
+			<-ctx.Done()
+			return nil
+		}, WithRecoverer(reporter, nil), WithStatus("test", NewStatusStore()))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+
+		var panicErr *PanicError
+		require.True(t, errors.As(err, &panicErr))
+		assert.Equal(t, "something went wrong", panicErr.Value)
+		assert.Equal(t, "test", panicErr.RunnableID)
+		assert.Equal(t, []string{"something went wrong"}, reporter.logs)
+	})
+
+	t.Run("children are cancelled when runFunc returns", func(t *testing.T) {
+		var cancelled int32
+
+		r := New(func(ctx context.Context) error {
+			Go(ctx, func(ctx context.Context) error {
+				<-ctx.Done()
+				atomic.AddInt32(&cancelled, 1)
+				return nil
+			})
+			return nil
+		})
+
+		err := r.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&cancelled))
+	})
+
+	t.Run("with retry, children of each attempt", func(t *testing.T) {
+		var running, maxRunning, consumers int32
+
+		r := New(func(ctx context.Context) error {
+			Go(ctx, func(ctx context.Context) error {
+				atomic.AddInt32(&consumers, 1)
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+						break
+					}
+				}
+
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			})
+			time.Sleep(10 * time.Millisecond)
+			return assert.AnError
+		}, WithRetry(3, ResetNever))
+
+		require.ErrorIs(t, r.Run(context.Background()), assert.AnError)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&consumers))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&maxRunning))
+		assert.Equal(t, int32(0), atomic.LoadInt32(&running))
+	})
+
+	t.Run("with retry, child error is retried", func(t *testing.T) {
+		attempts := 0
+		r := New(func(ctx context.Context) error {
+			attempts++
+			if attempts == 1 {
+				Go(ctx, func(ctx context.Context) error {
+					return assert.AnError
+				})
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return nil
+		}, WithRetry(3, ResetNever))
+
+		require.NoError(t, r.Run(context.Background()))
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("with retry, all children failed", func(t *testing.T) {
+		attempts := 0
+		r := New(func(ctx context.Context) error {
+			attempts++
+			Go(ctx, func(ctx context.Context) error {
+				return assert.AnError
+			})
+			<-ctx.Done()
+			return ctx.Err()
+		}, WithRetry(3, ResetNever))
+
+		err := r.Run(context.Background())
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NotErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("without runnable", func(t *testing.T) {
+		done := make(chan struct{})
+
+		Go(context.Background(), func(ctx context.Context) error {
+			defer close(done)
+			return assert.AnError
+		})
+
+		<-done
+	})
+}
@@ -26,7 +26,34 @@ import (
 //		// handle error
 //	}
 func NewGroup(runners ...Runnable) Runnable {
-	return New(func(ctx context.Context) error {
+	return NewGroupWithOptions(runners)
+}
+
+// NewGroupWithOptions creates a new Runnable that runs multiple runnables concurrently,
+// with the given options. If the group records its status with WithStatus, the
+// runnables of the group that record their status are registered under the ID of
+// the group, e.g. "api/http" for a runnable "http" in a group "api". Groups can be nested.
+//
+// Example:
+//
+//	store := runnable.NewStatusStore()
+//	api := runnable.NewGroupWithOptions([]runnable.Runnable{
+//		runnable.New(serveHTTP, runnable.WithStatus("http", store)),
+//		runnable.New(serveGRPC, runnable.WithStatus("grpc", store)),
+//		runnable.New(warmCache, runnable.WithStatus("cache", store), runnable.WithOptional()),
+//	}, runnable.WithStatus("api", store))
+//
+//	err := api.Run(context.Background())
+//	if err != nil {
+//		// handle error
+//	}
+func NewGroupWithOptions(runners []Runnable, options ...Option) Runnable {
+	g := &runnable{}
+	g.runFunc = func(ctx context.Context) error {
+		if scope, ok := ctx.Value(statusScopeKey{}).(*statusScope); ok && scope.owner == g {
+			ctx = context.WithValue(ctx, statusPrefixKey{}, scope.key)
+		}
+
 		grp, groupCtx := errgroup.WithContext(ctx)
 		for _, r := range runners {
 			r := r
@@ -35,5 +62,26 @@ func NewGroup(runners ...Runnable) Runnable {
 			})
 		}
 		return grp.Wait()
-	})
+	}
+
+	for _, option := range options {
+		option.apply(g)
+	}
+
+	return g
+}
+
+// statusPrefixKey is the context key of the status ID of the group a runnable runs in.
+type statusPrefixKey struct{}
+
+type withOptional struct{}
+
+// WithOptional marks the runnable as optional in its group: the group is not
+// degraded when the runnable is down (see StatusStore.Tree).
+func WithOptional() Option {
+	return withOptional{}
+}
+
+func (withOptional) apply(r *runnable) {
+	r.optional = true
 }
@@ -104,4 +104,67 @@ func TestRunnable(t *testing.T) {
 		require.Error(t, err, context.DeadlineExceeded)
 		assert.Equal(t, true, r.IsRunning())
 	})
+
+	t.Run("attempts", func(t *testing.T) {
+		type attempt struct {
+			number   int
+			runStart time.Time
+			start    time.Time
+		}
+		var attempts []attempt
+
+		counter := 0
+		r := New(func(ctx context.Context) error {
+			counter++
+			time.Sleep(time.Millisecond)
+			if counter < 3 {
+				return assert.AnError
+			}
+			return nil
+		}, WithRetry(3, ResetNever), optionFunc(func(r *runnable) {
+			r.chainHooks(func() {
+				attempts = append(attempts, attempt{r.attempt, r.runStart, r.attemptStart})
+			}, nil)
+		}))
+		require.NoError(t, r.Run(context.Background()))
+
+		require.Len(t, attempts, 3)
+		for i, a := range attempts {
+			assert.Equal(t, i+1, a.number)
+			assert.Equal(t, attempts[0].runStart, a.runStart)
+			assert.False(t, a.start.Before(a.runStart))
+			if i > 0 {
+				assert.True(t, a.start.After(attempts[i-1].start))
+			}
+		}
+	})
+
+	t.Run("id resolved before the run hooks", func(t *testing.T) {
+		store := NewStatusStore()
+
+		var ids []string
+		worker := New(func(ctx context.Context) error {
+			return nil
+		}, optionFunc(func(r *runnable) {
+			r.runHooks = append(r.runHooks, &idRecorder{owner: r, ids: &ids})
+		}), WithStatus("worker", store))
+
+		group := NewGroupWithOptions([]Runnable{worker}, WithStatus("api", store))
+		require.NoError(t, group.Run(context.Background()))
+
+		assert.Equal(t, []string{"api/worker"}, ids)
+	})
 }
+
+// idRecorder records the ID of its runnable when a run starts.
+type idRecorder struct {
+	owner *runnable
+	ids   *[]string
+}
+
+func (h *idRecorder) beforeRun(ctx context.Context) (context.Context, error) {
+	*h.ids = append(*h.ids, h.owner.id)
+	return ctx, nil
+}
+
+func (h *idRecorder) afterRun(ctx context.Context, err error) {}
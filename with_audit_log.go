@@ -0,0 +1,261 @@
+package runnable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// AuditEvent is the type of an AuditEntry.
+type AuditEvent string
+
+const (
+	// AuditRunStarted is logged when a run starts, before its first attempt.
+	AuditRunStarted AuditEvent = "run_started"
+	// AuditAttemptStarted is logged when an attempt of a run starts (see WithRetry).
+	AuditAttemptStarted AuditEvent = "attempt_started"
+	// AuditAttemptEnded is logged when an attempt of a run ends, with its error.
+	AuditAttemptEnded AuditEvent = "attempt_ended"
+	// AuditStopRequested is logged when a run is first asked to stop, with the cause
+	// and, when it was Stop, its caller.
+	AuditStopRequested AuditEvent = "stop_requested"
+	// AuditRunEnded is logged when a run ends, with the error returned by Run.
+	AuditRunEnded AuditEvent = "run_ended"
+)
+
+// AuditEntry is a lifecycle transition of a runnable, logged by WithAuditLog.
+type AuditEntry struct {
+	Time  time.Time
+	ID    string
+	Event AuditEvent
+	// Attempt is the number of the attempt, starting at 1. On AuditRunEnded, it is
+	// the number of attempts of the run.
+	Attempt int
+	// Error and ExitReason are set when an attempt or a run ends.
+	Error      string
+	ExitReason ExitReason
+	// Duration is the duration of the attempt or run that ended.
+	Duration time.Duration
+	// Cause and Caller are set on AuditStopRequested. Caller is the function and
+	// position of the caller of Stop; it is empty when the context was canceled.
+	Cause  string
+	Caller string
+}
+
+type auditEntryJSON struct {
+	Time            time.Time  `json:"time"`
+	ID              string     `json:"id"`
+	Event           AuditEvent `json:"event"`
+	Attempt         int        `json:"attempt,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	ExitReason      ExitReason `json:"exit_reason,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds,omitempty"`
+	Cause           string     `json:"cause,omitempty"`
+	Caller          string     `json:"caller,omitempty"`
+}
+
+// MarshalJSON encodes the entry with its duration in seconds.
+func (e AuditEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(auditEntryJSON{
+		Time:            e.Time,
+		ID:              e.ID,
+		Event:           e.Event,
+		Attempt:         e.Attempt,
+		Error:           e.Error,
+		ExitReason:      e.ExitReason,
+		DurationSeconds: e.Duration.Seconds(),
+		Cause:           e.Cause,
+		Caller:          e.Caller,
+	})
+}
+
+// UnmarshalJSON decodes an entry encoded by MarshalJSON.
+func (e *AuditEntry) UnmarshalJSON(data []byte) error {
+	var ej auditEntryJSON
+	if err := json.Unmarshal(data, &ej); err != nil {
+		return err
+	}
+
+	*e = AuditEntry{
+		Time:       ej.Time,
+		ID:         ej.ID,
+		Event:      ej.Event,
+		Attempt:    ej.Attempt,
+		Error:      ej.Error,
+		ExitReason: ej.ExitReason,
+		Duration:   seconds(ej.DurationSeconds),
+		Cause:      ej.Cause,
+		Caller:     ej.Caller,
+	}
+	return nil
+}
+
+// AuditLog appends audit entries as lines of JSON to an io.Writer, usually a
+// RotatingFile. It is safe for concurrent use. Use ReadAuditLog to read it back.
+type AuditLog struct {
+	w *jsonlWriter
+}
+
+// NewAuditLog creates a new AuditLog that writes to w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{
+		w: newJSONLWriter(w),
+	}
+}
+
+// OpenAuditLog creates a new AuditLog that appends to the file at path, rotated
+// like a RotatingFile. If the file ends with a line torn by a crash, a newline is
+// appended first so that the next entry starts on its own line. The file is closed
+// by Close.
+//
+// Example:
+//
+//	audit, err := runnable.OpenAuditLog("/var/log/app/audit.jsonl", 10<<20, 5)
+//	if err != nil {
+//		return err
+//	}
+//	defer audit.Close()
+//
+//	r := runnable.New(run, runnable.WithStatus("worker", store), runnable.WithAuditLog(audit))
+func OpenAuditLog(path string, maxSize int64, maxBackups int) (*AuditLog, error) {
+	torn, err := endsWithTornLine(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := NewRotatingFile(path, maxSize, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+	if torn {
+		if _, err := file.Write([]byte{'\n'}); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return NewAuditLog(file), nil
+}
+
+// endsWithTornLine returns true if the file at path is not empty and does not end
+// with a newline.
+func endsWithTornLine(path string) (bool, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() == 0 {
+		return false, nil
+	}
+
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
+
+// Log appends entry to the log.
+func (a *AuditLog) Log(entry AuditEntry) {
+	a.w.write(entry)
+}
+
+// Err returns the last error that occurred while writing an entry, if any, or
+// the error reported by the Err method of the writer, e.g. of a RotatingFile.
+func (a *AuditLog) Err() error {
+	return a.w.lastErr()
+}
+
+// Close closes the underlying writer, if it is an io.Closer.
+func (a *AuditLog) Close() error {
+	return a.w.close()
+}
+
+type withAuditLog struct {
+	log *AuditLog
+
+	owner *runnable
+}
+
+// WithAuditLog logs every lifecycle transition of the runnable to log: the start
+// and end of its runs and of their attempts, and the requests to stop it, with
+// their cause and the caller of Stop.
+func WithAuditLog(log *AuditLog) Option {
+	return optionFunc(func(r *runnable) {
+		w := &withAuditLog{
+			log:   log,
+			owner: r,
+		}
+
+		r.runHooks = append(r.runHooks, w)
+		r.chainHooks(w.attemptStarted, w.attemptEnded)
+		r.chainStopRequested(w.stopRequested)
+	})
+}
+
+func (w *withAuditLog) beforeRun(ctx context.Context) (context.Context, error) {
+	return ctx, nil
+}
+
+func (w *withAuditLog) afterRun(ctx context.Context, err error) {
+	entry := AuditEntry{
+		Time:       time.Now(),
+		ID:         w.owner.id,
+		Event:      AuditRunEnded,
+		Attempt:    w.owner.attempt,
+		ExitReason: exitReasonOf(err),
+		Duration:   time.Since(w.owner.runStart),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	w.log.Log(entry)
+}
+
+func (w *withAuditLog) attemptStarted() {
+	r := w.owner
+	if r.attempt == 1 {
+		w.log.Log(AuditEntry{Time: r.attemptStart, ID: r.id, Event: AuditRunStarted})
+	}
+	w.log.Log(AuditEntry{Time: r.attemptStart, ID: r.id, Event: AuditAttemptStarted, Attempt: r.attempt})
+}
+
+func (w *withAuditLog) attemptEnded(err error) {
+	entry := AuditEntry{
+		Time:       time.Now(),
+		ID:         w.owner.id,
+		Event:      AuditAttemptEnded,
+		Attempt:    w.owner.attempt,
+		ExitReason: exitReasonOf(err),
+		Duration:   time.Since(w.owner.attemptStart),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	w.log.Log(entry)
+}
+
+func (w *withAuditLog) stopRequested(req stopRequest) {
+	entry := AuditEntry{
+		Time:   req.time,
+		ID:     req.id,
+		Event:  AuditStopRequested,
+		Caller: req.caller,
+	}
+	if req.cause != nil {
+		entry.Cause = req.cause.Error()
+	}
+	w.log.Log(entry)
+}
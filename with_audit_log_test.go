@@ -0,0 +1,170 @@
+package runnable
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAuditLog(t *testing.T) {
+	events := func(entries []AuditEntry) []AuditEvent {
+		var evs []AuditEvent
+		for _, entry := range entries {
+			evs = append(evs, entry.Event)
+		}
+		return evs
+	}
+
+	t.Run("with audit log", func(t *testing.T) {
+		var buf bytes.Buffer
+		audit := NewAuditLog(&buf)
+
+		started := make(chan struct{})
+		attempts := 0
+		r := New(func(ctx context.Context) error {
+			attempts++
+			if attempts == 1 {
+				return assert.AnError
+			}
+			started <- struct{}{}
+			<-ctx.Done()
+			return nil
+		}, WithRetry(2, ResetNever), WithAuditLog(audit), WithStatus("test", NewStatusStore()))
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- r.Run(context.Background())
+		}()
+		<-started
+		require.NoError(t, r.Stop(context.Background()))
+		require.NoError(t, <-errCh)
+		require.NoError(t, audit.Err())
+
+		entries, _, err := ReadAuditEntries(&buf, AuditFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []AuditEvent{
+			AuditRunStarted,
+			AuditAttemptStarted,
+			AuditAttemptEnded,
+			AuditAttemptStarted,
+			AuditStopRequested,
+			AuditAttemptEnded,
+			AuditRunEnded,
+		}, events(entries))
+
+		for _, entry := range entries {
+			assert.Equal(t, "test", entry.ID)
+			assert.False(t, entry.Time.IsZero())
+		}
+
+		assert.Equal(t, 1, entries[2].Attempt)
+		assert.Equal(t, assert.AnError.Error(), entries[2].Error)
+		assert.Equal(t, ExitReasonError, entries[2].ExitReason)
+
+		assert.Equal(t, 2, entries[3].Attempt)
+
+		assert.Equal(t, "stop called", entries[4].Cause)
+		assert.Contains(t, entries[4].Caller, "TestWithAuditLog")
+		assert.Contains(t, entries[4].Caller, "with_audit_log_test.go")
+
+		assert.Equal(t, ExitReasonSuccess, entries[5].ExitReason)
+		assert.Equal(t, 2, entries[6].Attempt)
+		assert.GreaterOrEqual(t, entries[6].Duration, entries[5].Duration)
+	})
+
+	t.Run("with audit log, canceled context", func(t *testing.T) {
+		var buf bytes.Buffer
+		audit := NewAuditLog(&buf)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		r := New(func(ctx context.Context) error {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		}, WithAuditLog(audit), WithStatus("test", NewStatusStore()))
+		require.ErrorIs(t, r.Run(ctx), context.Canceled)
+
+		entries, _, err := ReadAuditEntries(&buf, AuditFilter{})
+		require.NoError(t, err)
+		require.Len(t, entries, 5)
+		assert.Equal(t, AuditStopRequested, entries[2].Event)
+		assert.Equal(t, context.Canceled.Error(), entries[2].Cause)
+		assert.Empty(t, entries[2].Caller)
+		assert.Equal(t, ExitReasonCanceled, entries[4].ExitReason)
+	})
+
+	t.Run("with audit log, group", func(t *testing.T) {
+		var buf bytes.Buffer
+		audit := NewAuditLog(&buf)
+		store := NewStatusStore()
+
+		group := NewGroupWithOptions([]Runnable{
+			New(func(ctx context.Context) error {
+				return nil
+			}, WithAuditLog(audit), WithStatus("http", store)),
+		}, WithStatus("api", store), WithAuditLog(audit))
+		require.NoError(t, group.Run(context.Background()))
+
+		entries, _, err := ReadAuditEntries(bytes.NewReader(buf.Bytes()), AuditFilter{ID: "api/http"})
+		require.NoError(t, err)
+		assert.Len(t, entries, 4)
+
+		entries, _, err = ReadAuditEntries(bytes.NewReader(buf.Bytes()), AuditFilter{ID: "api"})
+		require.NoError(t, err)
+		assert.Len(t, entries, 8)
+	})
+
+	t.Run("rotating audit log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+		audit, err := OpenAuditLog(path, 512, 10)
+		require.NoError(t, err)
+
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, WithAuditLog(audit), WithStatus("test", NewStatusStore()))
+		for i := 0; i < 5; i++ {
+			require.NoError(t, r.Run(context.Background()))
+		}
+		require.NoError(t, audit.Err())
+		require.NoError(t, audit.Close())
+
+		matches, err := filepath.Glob(path + ".*")
+		require.NoError(t, err)
+		assert.NotEmpty(t, matches)
+
+		entries, skipped, err := ReadAuditLog(path, AuditFilter{ID: "test"})
+		require.NoError(t, err)
+		assert.Zero(t, skipped)
+		require.Len(t, entries, 20)
+		for i, entry := range entries {
+			assert.Equal(t, []AuditEvent{AuditRunStarted, AuditAttemptStarted, AuditAttemptEnded, AuditRunEnded}[i%4], entry.Event)
+			if i > 0 {
+				assert.False(t, entry.Time.Before(entries[i-1].Time))
+			}
+		}
+	})
+
+	t.Run("torn audit log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		require.NoError(t, os.WriteFile(path, []byte(`{"time":"20`), 0o644))
+
+		audit, err := OpenAuditLog(path, 0, 0)
+		require.NoError(t, err)
+		audit.Log(AuditEntry{Time: time.Now(), ID: "test", Event: AuditRunStarted})
+		require.NoError(t, audit.Err())
+		require.NoError(t, audit.Close())
+
+		entries, skipped, err := ReadAuditLog(path, AuditFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, skipped)
+		require.Len(t, entries, 1)
+		assert.Equal(t, AuditRunStarted, entries[0].Event)
+	})
+}
@@ -0,0 +1,166 @@
+package runnable
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+type withLogger struct {
+	logger *slog.Logger
+
+	owner *runnable
+	scope *loggerScope
+
+	// ctx is the context of the run, with its logger, used for every record
+	ctx context.Context
+	// failed is the failure of the last attempt, logged once it is known whether
+	// the attempt is retried or ends the run.
+	failed *attemptFailure
+}
+
+// attemptFailure is a failed attempt that is not logged yet.
+type attemptFailure struct {
+	err   error
+	attrs []slog.Attr
+}
+
+// loggerScope is the logger of the run in progress; it is stored in the run context.
+type loggerScope struct {
+	logger atomic.Pointer[slog.Logger]
+}
+
+type loggerScopeKey struct{}
+
+// WithLogger logs the lifecycle of the runnable with logger: its start, the
+// failures of its attempts that are retried (see WithRetry), its retries and the
+// reason it stopped. Records have the "runnable_id" and "attempt" attributes,
+// and "duration", "exit_reason" and "error" when an attempt or the run ends.
+// Panics have the "panic" attribute. If logger is nil, slog.Default() is used.
+//
+// The logger, with the "runnable_id" and "attempt" attributes, is available to
+// runFunc with Logger.
+//
+// Example:
+//
+//	r := runnable.New(func(ctx context.Context) error {
+//		runnable.Logger(ctx).Info("processing")
+//		return nil
+//	}, runnable.WithStatus("worker", store), runnable.WithLogger(slog.Default()))
+func WithLogger(logger *slog.Logger) Option {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return optionFunc(func(r *runnable) {
+		w := &withLogger{
+			logger: logger,
+			owner:  r,
+		}
+
+		r.runHooks = append(r.runHooks, w)
+		r.chainHooks(w.attemptStarted, w.attemptStopped)
+	})
+}
+
+// Logger returns the logger of the runnable running with ctx, set by WithLogger,
+// with the "runnable_id" and "attempt" attributes of the current attempt. It
+// returns slog.Default() if the runnable has no logger.
+func Logger(ctx context.Context) *slog.Logger {
+	scope, ok := ctx.Value(loggerScopeKey{}).(*loggerScope)
+	if !ok {
+		return slog.Default()
+	}
+
+	logger := scope.logger.Load()
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
+
+func (w *withLogger) beforeRun(ctx context.Context) (context.Context, error) {
+	w.scope = &loggerScope{}
+	w.scope.logger.Store(w.logger.With(slog.String("runnable_id", w.owner.id)))
+	w.failed = nil
+	w.ctx = context.WithValue(ctx, loggerScopeKey{}, w.scope)
+	return w.ctx, nil
+}
+
+func (w *withLogger) afterRun(ctx context.Context, err error) {
+	// the failure of the last attempt is logged as the end of the run, unless the
+	// run ended otherwise, e.g. while waiting to retry
+	if w.failed != nil && !errors.Is(err, w.failed.err) {
+		w.logFailure()
+	}
+	w.failed = nil
+
+	reason := exitReasonOf(err)
+
+	level := slog.LevelInfo
+	if reason == ExitReasonError || reason == ExitReasonPanic {
+		level = slog.LevelError
+	}
+
+	attrs := []slog.Attr{
+		slog.Int("attempts", w.owner.attempt),
+		slog.Duration("duration", time.Since(w.owner.runStart)),
+		slog.String("exit_reason", string(reason)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	var p *PanicError
+	if errors.As(err, &p) {
+		attrs = append(attrs, slog.Any("panic", p.Value))
+	}
+	w.scope.logger.Load().LogAttrs(w.ctx, level, "runnable: stopped", attrs...)
+}
+
+func (w *withLogger) attemptStarted() {
+	if w.failed != nil {
+		w.logFailure()
+	}
+
+	// the logger of the attempt is built once per attempt
+	logger := w.logger.With(slog.String("runnable_id", w.owner.id), slog.Int("attempt", w.owner.attempt))
+	w.scope.logger.Store(logger)
+
+	if w.owner.attempt == 1 {
+		logger.LogAttrs(w.ctx, slog.LevelInfo, "runnable: started")
+	} else {
+		logger.LogAttrs(w.ctx, slog.LevelInfo, "runnable: retrying")
+	}
+}
+
+func (w *withLogger) attemptStopped(err error) {
+	reason := exitReasonOf(err)
+	if reason == ExitReasonSuccess || reason == ExitReasonCanceled {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.Duration("duration", time.Since(w.owner.attemptStart)),
+		slog.String("exit_reason", string(reason)),
+		slog.Any("error", err),
+	}
+
+	var p *PanicError
+	if errors.As(err, &p) {
+		attrs = append(attrs, slog.Any("panic", p.Value))
+	}
+	w.failed = &attemptFailure{err: err, attrs: attrs}
+}
+
+// logFailure logs the failure of the last attempt.
+func (w *withLogger) logFailure() {
+	level, msg := slog.LevelWarn, "runnable: attempt failed"
+	if exitReasonOf(w.failed.err) == ExitReasonPanic {
+		level, msg = slog.LevelError, "runnable: panicked"
+	}
+
+	w.scope.logger.Load().LogAttrs(w.ctx, level, msg, w.failed.attrs...)
+	w.failed = nil
+}
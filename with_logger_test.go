@@ -0,0 +1,228 @@
+package runnable
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLogger(t *testing.T) {
+	newLogger := func() (*slog.Logger, func() []map[string]interface{}) {
+		var (
+			buf bytes.Buffer
+			mu  sync.Mutex
+		)
+		logger := slog.New(slog.NewJSONHandler(&lockedWriter{w: &buf, mu: &mu}, nil))
+
+		records := func() []map[string]interface{} {
+			mu.Lock()
+			defer mu.Unlock()
+
+			var records []map[string]interface{}
+			dec := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+			for dec.More() {
+				var record map[string]interface{}
+				require.NoError(t, dec.Decode(&record))
+				records = append(records, record)
+			}
+			return records
+		}
+		return logger, records
+	}
+
+	t.Run("with logger", func(t *testing.T) {
+		logger, records := newLogger()
+
+		r := New(func(ctx context.Context) error {
+			Logger(ctx).Info("processing")
+			assert.Same(t, Logger(ctx), Logger(ctx))
+			return nil
+		}, WithLogger(logger), WithStatus("test", NewStatusStore()))
+		require.NoError(t, r.Run(context.Background()))
+
+		logs := records()
+		require.Len(t, logs, 3)
+
+		assert.Equal(t, "runnable: started", logs[0]["msg"])
+		assert.Equal(t, "test", logs[0]["runnable_id"])
+		assert.Equal(t, float64(1), logs[0]["attempt"])
+
+		assert.Equal(t, "processing", logs[1]["msg"])
+		assert.Equal(t, "test", logs[1]["runnable_id"])
+		assert.Equal(t, float64(1), logs[1]["attempt"])
+
+		assert.Equal(t, "runnable: stopped", logs[2]["msg"])
+		assert.Equal(t, "INFO", logs[2]["level"])
+		assert.Equal(t, "success", logs[2]["exit_reason"])
+		assert.Contains(t, logs[2], "duration")
+		assert.NotContains(t, logs[2], "error")
+	})
+
+	t.Run("with logger, with retry", func(t *testing.T) {
+		logger, records := newLogger()
+
+		attempts := 0
+		r := New(func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return assert.AnError
+			}
+			return nil
+		}, WithLogger(logger), WithRetry(3, ResetNever))
+		require.NoError(t, r.Run(context.Background()))
+
+		var msgs []interface{}
+		for _, log := range records() {
+			msgs = append(msgs, log["msg"])
+		}
+		assert.Equal(t, []interface{}{
+			"runnable: started",
+			"runnable: attempt failed",
+			"runnable: retrying",
+			"runnable: attempt failed",
+			"runnable: retrying",
+			"runnable: stopped",
+		}, msgs)
+
+		logs := records()
+		assert.Equal(t, "WARN", logs[1]["level"])
+		assert.Equal(t, float64(1), logs[1]["attempt"])
+		assert.Equal(t, "error", logs[1]["exit_reason"])
+		assert.Equal(t, assert.AnError.Error(), logs[1]["error"])
+		assert.Equal(t, float64(2), logs[2]["attempt"])
+		assert.Equal(t, float64(3), logs[5]["attempts"])
+	})
+
+	t.Run("with logger, error", func(t *testing.T) {
+		logger, records := newLogger()
+
+		r := New(func(ctx context.Context) error {
+			return assert.AnError
+		}, WithLogger(logger))
+		require.Error(t, r.Run(context.Background()))
+
+		// the failure is logged once, as the end of the run
+		logs := records()
+		require.Len(t, logs, 2)
+		assert.Equal(t, "runnable: stopped", logs[1]["msg"])
+		assert.Equal(t, "ERROR", logs[1]["level"])
+		assert.Equal(t, "error", logs[1]["exit_reason"])
+		assert.Equal(t, assert.AnError.Error(), logs[1]["error"])
+	})
+
+	t.Run("with logger, all attempts failed", func(t *testing.T) {
+		logger, records := newLogger()
+
+		r := New(func(ctx context.Context) error {
+			return assert.AnError
+		}, WithLogger(logger), WithRetry(2, ResetNever))
+		require.Error(t, r.Run(context.Background()))
+
+		var msgs []interface{}
+		for _, log := range records() {
+			msgs = append(msgs, log["msg"])
+		}
+		assert.Equal(t, []interface{}{
+			"runnable: started",
+			"runnable: attempt failed",
+			"runnable: retrying",
+			"runnable: stopped",
+		}, msgs)
+	})
+
+	t.Run("with logger, panic", func(t *testing.T) {
+		logger, records := newLogger()
+
+		r := New(func(ctx context.Context) error {
+			panic("boom")
+		}, WithLogger(logger), WithPanicReporter(PanicReporterFunc(func(ctx context.Context, p *PanicError) {})))
+		require.Error(t, r.Run(context.Background()))
+
+		logs := records()
+		require.Len(t, logs, 2)
+		assert.Equal(t, "runnable: stopped", logs[1]["msg"])
+		assert.Equal(t, "ERROR", logs[1]["level"])
+		assert.Equal(t, "boom", logs[1]["panic"])
+		assert.Equal(t, "panic", logs[1]["exit_reason"])
+	})
+
+	t.Run("with logger, panic, with retry", func(t *testing.T) {
+		logger, records := newLogger()
+
+		attempts := 0
+		r := New(func(ctx context.Context) error {
+			attempts++
+			if attempts == 1 {
+				panic("boom")
+			}
+			return nil
+		}, WithLogger(logger), WithPanicReporter(PanicReporterFunc(func(ctx context.Context, p *PanicError) {})), WithRetry(2, ResetNever))
+		require.NoError(t, r.Run(context.Background()))
+
+		logs := records()
+		require.Len(t, logs, 4)
+		assert.Equal(t, "runnable: panicked", logs[1]["msg"])
+		assert.Equal(t, "ERROR", logs[1]["level"])
+		assert.Equal(t, "boom", logs[1]["panic"])
+		assert.Equal(t, "runnable: retrying", logs[2]["msg"])
+	})
+
+	t.Run("run context", func(t *testing.T) {
+		var (
+			buf bytes.Buffer
+			mu  sync.Mutex
+		)
+		var values []interface{}
+		handler := &contextHandler{Handler: slog.NewJSONHandler(&lockedWriter{w: &buf, mu: &mu}, nil), values: &values}
+
+		ctx := context.WithValue(context.Background(), contextHandlerKey{}, "request-1")
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, WithLogger(slog.New(handler)))
+		require.NoError(t, r.Run(ctx))
+
+		assert.Equal(t, []interface{}{"request-1", "request-1"}, values)
+	})
+
+	t.Run("without logger", func(t *testing.T) {
+		assert.Equal(t, slog.Default(), Logger(context.Background()))
+	})
+}
+
+type contextHandlerKey struct{}
+
+// contextHandler records the contextHandlerKey value of the context of each record.
+type contextHandler struct {
+	slog.Handler
+	values *[]interface{}
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	*h.values = append(*h.values, ctx.Value(contextHandlerKey{}))
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), values: h.values}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), values: h.values}
+}
+
+type lockedWriter struct {
+	w  *bytes.Buffer
+	mu *sync.Mutex
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
@@ -0,0 +1,98 @@
+package runnable
+
+import (
+	"context"
+	"time"
+)
+
+// Metrics records time-series metrics of runnables, labeled with the runnable ID.
+// It is a small interface, so that runnables can be measured without depending
+// on a metrics library; MemoryMetrics and ExpvarMetrics are provided.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// AddCounter adds delta to the counter name of the runnable id.
+	AddCounter(name, id string, delta float64)
+	// SetGauge sets the gauge name of the runnable id to value.
+	SetGauge(name, id string, value float64)
+	// ObserveHistogram adds value to the histogram name of the runnable id.
+	ObserveHistogram(name, id string, value float64)
+}
+
+// Names of the metrics recorded by WithMetrics. Like in StatusStore, each attempt
+// of a run (see WithRetry) counts as a run.
+const (
+	// MetricRuns is the counter of runs.
+	MetricRuns = "runnable_runs_total"
+	// MetricFailures is the counter of runs that ended with an error other than a
+	// cancellation, panics included.
+	MetricFailures = "runnable_failures_total"
+	// MetricPanics is the counter of runs that panicked.
+	MetricPanics = "runnable_panics_total"
+	// MetricRetries is the counter of runs started by WithRetry after a failure.
+	MetricRetries = "runnable_retries_total"
+	// MetricRunning is the gauge set to 1 while the runnable is running, 0 otherwise.
+	MetricRunning = "runnable_running"
+	// MetricRunDuration is the histogram of the durations of the runs, in seconds.
+	MetricRunDuration = "runnable_run_duration_seconds"
+	// MetricStopLatency is the histogram of the time it took the runnable to stop
+	// after it was asked to, by Stop or by the cancellation of its context, in seconds.
+	MetricStopLatency = "runnable_stop_latency_seconds"
+)
+
+type withMetrics struct {
+	metrics Metrics
+
+	owner *runnable
+}
+
+// WithMetrics records the metrics of the runnable in m, under the ID of the
+// runnable (see WithStatus). The names of the metrics are the Metric constants.
+//
+// Example:
+//
+//	metrics := runnable.NewExpvarMetrics("runnables")
+//	r := runnable.New(run, runnable.WithStatus("worker", store), runnable.WithMetrics(metrics))
+func WithMetrics(m Metrics) Option {
+	return optionFunc(func(r *runnable) {
+		w := &withMetrics{
+			metrics: m,
+			owner:   r,
+		}
+
+		r.runHooks = append(r.runHooks, w)
+		r.chainHooks(w.attemptStarted, w.attemptStopped)
+	})
+}
+
+func (w *withMetrics) beforeRun(ctx context.Context) (context.Context, error) {
+	return ctx, nil
+}
+
+func (w *withMetrics) afterRun(ctx context.Context, err error) {
+	if req, ok := w.owner.stopRequest(); ok {
+		w.metrics.ObserveHistogram(MetricStopLatency, w.owner.id, time.Since(req.time).Seconds())
+	}
+}
+
+func (w *withMetrics) attemptStarted() {
+	id := w.owner.id
+	w.metrics.AddCounter(MetricRuns, id, 1)
+	if w.owner.attempt > 1 {
+		w.metrics.AddCounter(MetricRetries, id, 1)
+	}
+	w.metrics.SetGauge(MetricRunning, id, 1)
+}
+
+func (w *withMetrics) attemptStopped(err error) {
+	id := w.owner.id
+	w.metrics.SetGauge(MetricRunning, id, 0)
+	w.metrics.ObserveHistogram(MetricRunDuration, id, time.Since(w.owner.attemptStart).Seconds())
+
+	switch exitReasonOf(err) {
+	case ExitReasonPanic:
+		w.metrics.AddCounter(MetricPanics, id, 1)
+		w.metrics.AddCounter(MetricFailures, id, 1)
+	case ExitReasonError:
+		w.metrics.AddCounter(MetricFailures, id, 1)
+	}
+}
@@ -0,0 +1,93 @@
+package runnable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMetrics(t *testing.T) {
+
+	t.Run("with metrics", func(t *testing.T) {
+		metrics := NewMemoryMetrics()
+
+		started := make(chan struct{})
+		r := New(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}, WithMetrics(metrics), WithStatus("test", NewStatusStore()))
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- r.Run(context.Background())
+		}()
+		<-started
+
+		assert.Equal(t, float64(1), metrics.Counter(MetricRuns, "test"))
+		assert.Equal(t, float64(1), metrics.Gauge(MetricRunning, "test"))
+
+		require.NoError(t, r.Stop(context.Background()))
+		require.NoError(t, <-errCh)
+
+		assert.Equal(t, float64(0), metrics.Gauge(MetricRunning, "test"))
+		assert.Equal(t, float64(0), metrics.Counter(MetricFailures, "test"))
+		assert.Equal(t, float64(0), metrics.Counter(MetricRetries, "test"))
+		require.Len(t, metrics.Histogram(MetricRunDuration, "test"), 1)
+
+		latencies := metrics.Histogram(MetricStopLatency, "test")
+		require.Len(t, latencies, 1)
+		assert.GreaterOrEqual(t, latencies[0], (10 * time.Millisecond).Seconds())
+	})
+
+	t.Run("with metrics, with retry", func(t *testing.T) {
+		for _, metricsFirst := range []bool{true, false} {
+			metrics := NewMemoryMetrics()
+
+			opts := []Option{WithMetrics(metrics), WithRetry(4, ResetNever), WithStatus("test", NewStatusStore())}
+			if !metricsFirst {
+				opts[0], opts[1] = opts[1], opts[0]
+			}
+
+			attempts := 0
+			r := New(func(ctx context.Context) error {
+				attempts++
+				switch attempts {
+				case 1:
+					return assert.AnError
+				case 2:
+					panic("boom")
+				default:
+					return nil
+				}
+			}, append([]Option{WithPanicReporter(PanicReporterFunc(func(ctx context.Context, p *PanicError) {}))}, opts...)...)
+			require.NoError(t, r.Run(context.Background()))
+
+			assert.Equal(t, float64(3), metrics.Counter(MetricRuns, "test"))
+			assert.Equal(t, float64(2), metrics.Counter(MetricRetries, "test"))
+			assert.Equal(t, float64(2), metrics.Counter(MetricFailures, "test"))
+			assert.Equal(t, float64(1), metrics.Counter(MetricPanics, "test"))
+			assert.Len(t, metrics.Histogram(MetricRunDuration, "test"), 3)
+			assert.Empty(t, metrics.Histogram(MetricStopLatency, "test"))
+		}
+	})
+
+	t.Run("with metrics, canceled context", func(t *testing.T) {
+		metrics := NewMemoryMetrics()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		r := New(func(ctx context.Context) error {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		}, WithMetrics(metrics), WithStatus("test", NewStatusStore()))
+		require.ErrorIs(t, r.Run(ctx), context.Canceled)
+
+		assert.Equal(t, float64(0), metrics.Counter(MetricFailures, "test"))
+		assert.Len(t, metrics.Histogram(MetricStopLatency, "test"), 1)
+	})
+}
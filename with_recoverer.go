@@ -3,7 +3,9 @@ package runnable
 import (
 	"context"
 	"fmt"
+	"log"
 	"runtime/debug"
+	"time"
 )
 
 type RecoveryReporter interface {
@@ -14,50 +16,218 @@ type StackPrinter interface {
 	Print(ctx context.Context, callstack []byte)
 }
 
+// PanicReporter receives every panic recovered by a runnable, along with the
+// stack and the runnable it happened in.
+type PanicReporter interface {
+	ReportPanic(ctx context.Context, p *PanicError)
+}
+
+// PanicReporterFunc is an adapter to allow the use of ordinary functions as PanicReporter.
+type PanicReporterFunc func(ctx context.Context, p *PanicError)
+
+func (f PanicReporterFunc) ReportPanic(ctx context.Context, p *PanicError) {
+	f(ctx, p)
+}
+
+// PanicError is the error returned by a runnable when its runFunc panics and the
+// panic is recovered. Use errors.As to retrieve it from the error returned by Run.
+//
+// Example:
+//
+//	var panicErr *runnable.PanicError
+//	if errors.As(err, &panicErr) {
+//		log.Printf("runnable %s panicked: %v\n%s", panicErr.RunnableID, panicErr.Value, panicErr.Stack)
+//	}
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack of the panicking goroutine.
+	Stack []byte
+	// RunnableID is the ID of the runnable, if it has one (see WithStatus).
+	RunnableID string
+	// Time is the time the panic was recovered.
+	Time time.Time
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error, so that errors.Is and
+// errors.As can look through panics such as panic(err).
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
 // NoopReporter
 // Used to continue running go routine  and do nothing
 type NoopReporter struct{}
 
 func (*NoopReporter) Report(ctx context.Context, rec interface{}) {}
 
+// RecoveryReporterAdapter adapts a RecoveryReporter to the PanicReporter interface.
+// The reporter receives the panic value.
+func RecoveryReporterAdapter(reporter RecoveryReporter) PanicReporter {
+	return &recoveryReporterAdapter{
+		reporter: reporter,
+	}
+}
+
+type recoveryReporterAdapter struct {
+	reporter RecoveryReporter
+}
+
+func (a *recoveryReporterAdapter) ReportPanic(ctx context.Context, p *PanicError) {
+	a.reporter.Report(ctx, p.Value)
+}
+
+func (a *recoveryReporterAdapter) Flush() error {
+	if flusher, ok := a.reporter.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// StackPrinterAdapter adapts a StackPrinter to the PanicReporter interface.
+// The printer receives the stack of the panicking goroutine.
+func StackPrinterAdapter(printer StackPrinter) PanicReporter {
+	return &stackPrinterAdapter{
+		printer: printer,
+	}
+}
+
+type stackPrinterAdapter struct {
+	printer StackPrinter
+}
+
+func (a *stackPrinterAdapter) ReportPanic(ctx context.Context, p *PanicError) {
+	a.printer.Print(ctx, p.Stack)
+}
+
+func (a *stackPrinterAdapter) Flush() error {
+	if flusher, ok := a.printer.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
 type recoverer struct {
-	reporter     RecoveryReporter
-	stackPrinter StackPrinter
+	reporters []PanicReporter
+	mode      RecoveryMode
 }
 
-func WithRecoverer(reporter RecoveryReporter, stackPrinter StackPrinter) Option {
+// WithRecoverer recovers panics in runFunc and returns them as *PanicError. The
+// stack is printed with stackPrinter and the panic value is reported to reporter;
+// either may be nil. An optional RecoveryMode sets what happens next, see Recover
+// (the default), ReportAndRepanic and CrashAfter.
+//
+// Example:
+//
+//	r := runnable.New(run, runnable.WithRecoverer(reporter, printer, runnable.CrashAfter(3, time.Minute)))
+func WithRecoverer(reporter RecoveryReporter, stackPrinter StackPrinter, mode ...RecoveryMode) Option {
+	rec := &recoverer{}
+	if len(mode) > 0 {
+		rec.mode = mode[len(mode)-1]
+	}
+	if stackPrinter != nil {
+		rec.reporters = append(rec.reporters, StackPrinterAdapter(stackPrinter))
+	}
+	if reporter != nil {
+		rec.reporters = append(rec.reporters, RecoveryReporterAdapter(reporter))
+	}
+	return rec
+}
+
+// WithPanicReporter recovers panics in runFunc and returns them as *PanicError.
+// Every recovered panic is reported to the given reporters, in order.
+//
+// Example:
+//
+//	r := runnable.New(run, runnable.WithPanicReporter(runnable.PanicReporterFunc(
+//		func(ctx context.Context, p *runnable.PanicError) {
+//			log.Printf("%s: %v\n%s", p.RunnableID, p.Value, p.Stack)
+//		},
+//	)))
+func WithPanicReporter(reporters ...PanicReporter) Option {
 	return &recoverer{
-		reporter:     reporter,
-		stackPrinter: stackPrinter,
+		reporters: reporters,
 	}
 }
 
 func (rec *recoverer) apply(r *runnable) {
+	r.panicReporters = append(r.panicReporters, rec.reporters...)
+	if rec.mode != nil {
+		r.recoveryMode = rec.mode
+	}
+
 	originalRunFunc := r.runFunc
-	r.runFunc = func(ctx context.Context) error {
-		var err error
-		innerRun := func(ctx context.Context) error {
-			defer func() {
-				if recovery := recover(); recovery != nil {
-					err = fmt.Errorf("panic: %v", recovery)
-
-					if rec.stackPrinter != nil {
-						rec.stackPrinter.Print(ctx, debug.Stack())
-					}
-
-					if rec.reporter != nil {
-						rec.reporter.Report(ctx, recovery)
-					}
-				}
-			}()
-
-			return originalRunFunc(ctx)
+	r.runFunc = func(ctx context.Context) (err error) {
+		defer func() {
+			if recovery := recover(); recovery != nil {
+				p := r.newPanicError(recovery, debug.Stack())
+				r.handlePanic(ctx, p)
+				err = p
+			}
+		}()
+
+		return originalRunFunc(ctx)
+	}
+}
+
+func (r *runnable) newPanicError(value interface{}, stack []byte) *PanicError {
+	return &PanicError{
+		Value:      value,
+		Stack:      stack,
+		RunnableID: r.id,
+		Time:       time.Now(),
+	}
+}
+
+// handlePanic reports p and applies the recovery mode of the runnable.
+func (r *runnable) handlePanic(ctx context.Context, p *PanicError) {
+	r.reportPanic(ctx, p)
+
+	if r.recoveryMode != nil {
+		r.recoveryMode.afterPanic(r, p)
+	}
+}
+
+// reportPanic reports p to every panic reporter of the runnable. A panic raised
+// by a reporter is recovered and logged, and does not prevent the remaining
+// reporters from being called.
+func (r *runnable) reportPanic(ctx context.Context, p *PanicError) {
+	for _, reporter := range r.panicReporters {
+		callPanicReporter(ctx, reporter, p)
+	}
+}
+
+func callPanicReporter(ctx context.Context, reporter PanicReporter, p *PanicError) {
+	defer func() {
+		if recovery := recover(); recovery != nil {
+			log.Printf("runnable: panic in panic reporter: %v\n%s", recovery, debug.Stack())
 		}
+	}()
 
-		if errInner := innerRun(ctx); errInner != nil {
-			return errInner
+	reporter.ReportPanic(ctx, p)
+}
+
+// safeCall calls fn, recovering any panic raised by it. The panic is reported to
+// the panic reporters of the runnable, or logged if it has none, the recovery
+// mode of the runnable is applied, and the panic is returned.
+func (r *runnable) safeCall(ctx context.Context, fn func()) (p *PanicError) {
+	defer func() {
+		if recovery := recover(); recovery != nil {
+			p = r.newPanicError(recovery, debug.Stack())
+			if len(r.panicReporters) == 0 {
+				log.Printf("runnable %q: %v\n%s", p.RunnableID, p, p.Stack)
+			}
+			r.handlePanic(ctx, p)
 		}
+	}()
 
-		return err
-	}
+	fn()
+	return nil
 }
@@ -2,6 +2,7 @@ package runnable
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -98,4 +99,187 @@ func TestWithRecoverer(t *testing.T) {
 		require.Equal(t, false, s["test"].Running)
 		require.Error(t, s["test"].LastError)
 	})
+
+	t.Run("panic error", func(t *testing.T) {
+		var printed []byte
+		printer := stackPrinterFunc(func(ctx context.Context, callstack []byte) {
+			printed = callstack
+		})
+
+		store := NewStatusStore()
+		r := New(func(ctx context.Context) error {
+			panic("something went wrong")
+		}, WithRecoverer(nil, printer), WithStatus("test", store))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+
+		var panicErr *PanicError
+		require.True(t, errors.As(err, &panicErr))
+		assert.Equal(t, "something went wrong", panicErr.Value)
+		assert.Equal(t, "test", panicErr.RunnableID)
+		assert.False(t, panicErr.Time.IsZero())
+		assert.Contains(t, string(panicErr.Stack), "runtime/debug.Stack")
+		assert.Equal(t, panicErr.Stack, printed)
+		assert.Equal(t, "panic: something went wrong", err.Error())
+	})
+
+	t.Run("panic error, unwrap", func(t *testing.T) {
+		r := New(func(ctx context.Context) error {
+			panic(assert.AnError)
+		}, WithRecoverer(nil, nil))
+
+		err := r.Run(context.Background())
+		require.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("with panic reporter", func(t *testing.T) {
+		var reported []*PanicError
+		reporter := PanicReporterFunc(func(ctx context.Context, p *PanicError) {
+			reported = append(reported, p)
+		})
+
+		r := New(func(ctx context.Context) error {
+			panic("something went wrong")
+		}, WithStatus("test", NewStatusStore()), WithPanicReporter(reporter))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		require.Len(t, reported, 1)
+		assert.Equal(t, "something went wrong", reported[0].Value)
+		assert.Equal(t, "test", reported[0].RunnableID)
+
+		var panicErr *PanicError
+		require.True(t, errors.As(err, &panicErr))
+		assert.Same(t, reported[0], panicErr)
+	})
+}
+
+func TestPanicSafety(t *testing.T) {
+	t.Run("panic in hooks", func(t *testing.T) {
+		var reported []*PanicError
+		reporter := PanicReporterFunc(func(ctx context.Context, p *PanicError) {
+			reported = append(reported, p)
+		})
+
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, WithPanicReporter(reporter), hooksOption{
+			onStart: func() { panic("start") },
+			onStop:  func(error) { panic("stop") },
+		})
+
+		err := r.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, false, r.IsRunning())
+		require.Len(t, reported, 2)
+		assert.Equal(t, "start", reported[0].Value)
+		assert.Equal(t, "stop", reported[1].Value)
+
+		// the runnable can be run again
+		err = r.Run(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, reported, 4)
+	})
+
+	t.Run("panic in hooks, with retry", func(t *testing.T) {
+		counter := 0
+		r := New(func(ctx context.Context) error {
+			defer func() { counter++ }()
+			if counter < 2 {
+				return assert.AnError
+			}
+			return nil
+		}, WithRetry(3, ResetNever), hooksOption{
+			onStart: func() { panic("start") },
+			onStop:  func(error) { panic("stop") },
+		})
+
+		err := r.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, counter)
+		assert.Equal(t, false, r.IsRunning())
+	})
+
+	t.Run("panic in a chained hook", func(t *testing.T) {
+		var reported []*PanicError
+		reporter := PanicReporterFunc(func(ctx context.Context, p *PanicError) {
+			reported = append(reported, p)
+		})
+		store := NewStatusStore()
+
+		started := make(chan struct{})
+		r := New(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			return nil
+		}, WithPanicReporter(reporter), WithStatus("test", store), WithTracer(attemptPanicTracer{NewRecordingTracer()}))
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- r.Run(context.Background())
+		}()
+		<-started
+
+		// the hook of WithStatus is called even though the one of WithTracer panicked
+		st, ok := store.GetOne("test")
+		require.True(t, ok)
+		assert.True(t, st.Running)
+
+		require.NoError(t, r.Stop(context.Background()))
+		require.NoError(t, <-errCh)
+
+		st, _ = store.GetOne("test")
+		assert.False(t, st.Running)
+		assert.Equal(t, 1, st.Runs)
+		require.Len(t, reported, 1)
+		assert.Equal(t, "attempt span", reported[0].Value)
+	})
+
+	t.Run("panic in reporter and stack printer", func(t *testing.T) {
+		reporter := &InMemoryReporter{}
+		printer := stackPrinterFunc(func(ctx context.Context, callstack []byte) {
+			panic("printer")
+		})
+
+		r := New(func(ctx context.Context) error {
+			panic("something went wrong")
+		}, WithRecoverer(reporter, printer), WithPanicReporter(PanicReporterFunc(func(ctx context.Context, p *PanicError) {
+			panic("reporter")
+		})))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, "panic: something went wrong", err.Error())
+		assert.Equal(t, []string{"something went wrong"}, reporter.logs)
+		assert.Equal(t, false, r.IsRunning())
+	})
+}
+
+type hooksOption struct {
+	onStart func()
+	onStop  func(error)
+}
+
+func (h hooksOption) apply(r *runnable) {
+	r.onStart = h.onStart
+	r.onStop = h.onStop
+}
+
+// attemptPanicTracer panics when an attempt span is started.
+type attemptPanicTracer struct {
+	Tracer
+}
+
+func (t attemptPanicTracer) StartSpan(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, Span) {
+	if name == SpanNameAttempt {
+		panic("attempt span")
+	}
+	return t.Tracer.StartSpan(ctx, name, attrs)
+}
+
+type stackPrinterFunc func(ctx context.Context, callstack []byte)
+
+func (f stackPrinterFunc) Print(ctx context.Context, callstack []byte) {
+	f(ctx, callstack)
 }
@@ -0,0 +1,148 @@
+package runnable
+
+import (
+	"log"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// PanicExitCode is the exit code used by CrashAfter once the panic budget is spent.
+const PanicExitCode = 70
+
+// osExit is replaced in tests.
+var osExit = os.Exit
+
+// Flusher is implemented by panic reporters that buffer their output. Reporters
+// are flushed before CrashAfter exits the process.
+type Flusher interface {
+	Flush() error
+}
+
+// RecoveryMode decides what happens after a panic has been recovered and reported.
+// The default mode is Recover.
+type RecoveryMode interface {
+	afterPanic(r *runnable, p *PanicError)
+}
+
+type recoverMode struct{}
+
+func (recoverMode) afterPanic(r *runnable, p *PanicError) {}
+
+// Recover returns a RecoveryMode that turns panics into *PanicError errors. This is the default.
+func Recover() RecoveryMode {
+	return recoverMode{}
+}
+
+type repanicMode struct{}
+
+func (repanicMode) afterPanic(r *runnable, p *PanicError) {
+	r.flushPanicReporters()
+
+	// the new panic has the stack of the recoverer, print the one of the fault
+	log.Printf("runnable %q: %v\n%s", p.RunnableID, p, p.Stack)
+	panic(p)
+}
+
+// ReportAndRepanic returns a RecoveryMode that panics again once the panic has been
+// reported. The new panic value is the *PanicError, whose Stack is the stack of the
+// original panic; that stack is also logged, as the crash output shows the stack
+// of the new panic.
+func ReportAndRepanic() RecoveryMode {
+	return repanicMode{}
+}
+
+type crashAfterMode struct {
+	n      int
+	within time.Duration
+
+	panics []time.Time
+
+	mu sync.Mutex
+}
+
+// CrashAfter returns a RecoveryMode that recovers panics until n of them happened
+// within the given window, then flushes the panic reporters and exits the process
+// with PanicExitCode. A window of zero counts every panic since the process started.
+//
+// The panic budget is shared by all the runnables using the same RecoveryMode.
+//
+// Example:
+//
+//	crash := runnable.CrashAfter(3, time.Minute)
+//
+//	r1 := runnable.New(run1, runnable.WithRecoverer(reporter, printer, crash))
+//	r2 := runnable.New(run2, runnable.WithRecoverer(reporter, printer, crash))
+func CrashAfter(n int, within time.Duration) RecoveryMode {
+	if n < 1 {
+		n = 1
+	}
+
+	return &crashAfterMode{
+		n:      n,
+		within: within,
+	}
+}
+
+func (c *crashAfterMode) afterPanic(r *runnable, p *PanicError) {
+	if !c.spend(p.Time) {
+		return
+	}
+
+	r.flushPanicReporters()
+	osExit(PanicExitCode)
+}
+
+// spend records a panic at t and returns true if the panic budget is spent.
+func (c *crashAfterMode) spend(t time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.panics = append(c.panics, t)
+	if c.within > 0 {
+		i := 0
+		for i < len(c.panics) && t.Sub(c.panics[i]) > c.within {
+			i++
+		}
+		c.panics = c.panics[i:]
+	}
+
+	return len(c.panics) >= c.n
+}
+
+type withRecoveryMode struct {
+	mode RecoveryMode
+}
+
+// WithRecoveryMode sets what happens after a panic recovered by the runnable has
+// been reported: see Recover, ReportAndRepanic and CrashAfter. It applies to panics
+// recovered by WithRecoverer and WithPanicReporter; the latter takes its reporters
+// as variadic arguments, so it has no room for a mode of its own.
+func WithRecoveryMode(mode RecoveryMode) Option {
+	return &withRecoveryMode{
+		mode: mode,
+	}
+}
+
+func (w *withRecoveryMode) apply(r *runnable) {
+	r.recoveryMode = w.mode
+}
+
+func (r *runnable) flushPanicReporters() {
+	for _, reporter := range r.panicReporters {
+		if flusher, ok := reporter.(Flusher); ok {
+			flushPanicReporter(flusher)
+		}
+	}
+}
+
+func flushPanicReporter(flusher Flusher) {
+	defer func() {
+		if recovery := recover(); recovery != nil {
+			log.Printf("runnable: panic in panic reporter: %v\n%s", recovery, debug.Stack())
+		}
+	}()
+
+	_ = flusher.Flush()
+}
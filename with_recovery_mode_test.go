@@ -0,0 +1,97 @@
+package runnable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRecoveryMode(t *testing.T) {
+
+	t.Run("recover", func(t *testing.T) {
+		reporter := &InMemoryReporter{}
+
+		r := New(func(ctx context.Context) error {
+			panic("something went wrong")
+		}, WithRecoverer(reporter, nil, Recover()))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, []string{"something went wrong"}, reporter.logs)
+	})
+
+	t.Run("report and repanic", func(t *testing.T) {
+		reporter := &InMemoryReporter{}
+
+		r := New(func(ctx context.Context) error {
+			panic("something went wrong")
+		}, WithRecoverer(reporter, nil, ReportAndRepanic()))
+
+		var recovered interface{}
+		func() {
+			defer func() { recovered = recover() }()
+			_ = r.Run(context.Background())
+		}()
+
+		p, ok := recovered.(*PanicError)
+		require.True(t, ok)
+		assert.Equal(t, "something went wrong", p.Value)
+		assert.Contains(t, string(p.Stack), "TestWithRecoveryMode.func2.1")
+		assert.Equal(t, []string{"something went wrong"}, reporter.logs)
+		assert.Equal(t, false, r.IsRunning())
+	})
+
+	t.Run("crash after", func(t *testing.T) {
+		var exitCodes []int
+		osExit = func(code int) { exitCodes = append(exitCodes, code) }
+		defer func() { osExit = osExitDefault }()
+
+		reporter := &flushingReporter{}
+		mode := CrashAfter(3, time.Minute)
+
+		newRunnable := func() Runnable {
+			return New(func(ctx context.Context) error {
+				panic("something went wrong")
+			}, WithPanicReporter(reporter), WithRecoveryMode(mode))
+		}
+
+		r1, r2 := newRunnable(), newRunnable()
+		require.Error(t, r1.Run(context.Background()))
+		require.Error(t, r2.Run(context.Background()))
+		assert.Empty(t, exitCodes)
+		assert.Equal(t, 0, reporter.flushed)
+
+		require.Error(t, r1.Run(context.Background()))
+		assert.Equal(t, []int{PanicExitCode}, exitCodes)
+		assert.Equal(t, 3, reporter.reported)
+		assert.Equal(t, 1, reporter.flushed)
+	})
+
+	t.Run("crash after, window", func(t *testing.T) {
+		mode := CrashAfter(2, 50*time.Millisecond).(*crashAfterMode)
+
+		now := time.Now()
+		assert.False(t, mode.spend(now))
+		assert.False(t, mode.spend(now.Add(100*time.Millisecond)))
+		assert.True(t, mode.spend(now.Add(120*time.Millisecond)))
+	})
+}
+
+var osExitDefault = osExit
+
+type flushingReporter struct {
+	reported int
+	flushed  int
+}
+
+func (f *flushingReporter) ReportPanic(ctx context.Context, p *PanicError) {
+	f.reported++
+}
+
+func (f *flushingReporter) Flush() error {
+	f.flushed++
+	return nil
+}
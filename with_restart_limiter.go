@@ -0,0 +1,115 @@
+package runnable
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RestartLimiter is a token bucket that caps the aggregate restart rate of all
+// runnables it is shared with. Each restart performed by WithRetry consumes one
+// token; tokens are refilled at a rate of one per interval up to burst.
+//
+// Restarts that cannot get a token right away are queued and spaced out by the
+// refill interval. A queued restart is abandoned as soon as the runnable is stopped.
+type RestartLimiter struct {
+	every time.Duration
+	burst int
+
+	tokens float64
+	last   time.Time
+
+	mu sync.Mutex
+}
+
+// NewRestartLimiter creates a new RestartLimiter that allows one restart every
+// interval, with bursts of up to burst restarts. An interval of zero disables limiting.
+//
+// Example:
+//
+//	limiter := runnable.NewRestartLimiter(time.Second, 5)
+//
+//	r1 := runnable.New(run1, runnable.WithRetry(10, runnable.ResetNever), runnable.WithRestartLimiter(limiter))
+//	r2 := runnable.New(run2, runnable.WithRetry(10, runnable.ResetNever), runnable.WithRestartLimiter(limiter))
+func NewRestartLimiter(every time.Duration, burst int) *RestartLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RestartLimiter{
+		every:  every,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a restart is allowed or the context is done. If the context
+// is done first, the reserved token is returned to the bucket and the context
+// error is returned.
+func (l *RestartLimiter) Wait(ctx context.Context) error {
+	if l.every <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	l.advance(time.Now())
+	l.tokens--
+
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens * float64(l.every))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.advance(time.Now())
+		l.tokens++
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *RestartLimiter) advance(now time.Time) {
+	elapsed := now.Sub(l.last)
+	if elapsed <= 0 {
+		return
+	}
+
+	l.last = now
+	l.tokens += float64(elapsed) / float64(l.every)
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+}
+
+type withRestartLimiter struct {
+	limiter *RestartLimiter
+}
+
+// WithRestartLimiter makes every restart performed by WithRetry wait for the given
+// limiter. The same limiter can be shared by many runnables to cap their aggregate
+// restart rate, e.g. to avoid stampeding a shared dependency when it recovers.
+func WithRestartLimiter(limiter *RestartLimiter) Option {
+	return &withRestartLimiter{
+		limiter: limiter,
+	}
+}
+
+func (w *withRestartLimiter) apply(r *runnable) {
+	r.restartLimiter = w.limiter
+}
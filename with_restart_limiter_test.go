@@ -0,0 +1,102 @@
+package runnable
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRestartLimiter(t *testing.T) {
+
+	t.Run("limiter, burst", func(t *testing.T) {
+		limiter := NewRestartLimiter(time.Hour, 2)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		require.NoError(t, limiter.Wait(ctx))
+		require.NoError(t, limiter.Wait(ctx))
+		require.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("limiter, disabled", func(t *testing.T) {
+		limiter := NewRestartLimiter(0, 1)
+
+		for i := 0; i < 100; i++ {
+			require.NoError(t, limiter.Wait(context.Background()))
+		}
+	})
+
+	t.Run("shared across runnables", func(t *testing.T) {
+		limiter := NewRestartLimiter(100*time.Millisecond, 1)
+
+		var attempts int32
+		newRunnable := func() Runnable {
+			return New(func(ctx context.Context) error {
+				atomic.AddInt32(&attempts, 1)
+				return assert.AnError
+			}, WithRetry(3, ResetNever), WithRestartLimiter(limiter))
+		}
+
+		start := time.Now()
+
+		errCh := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			r := newRunnable()
+			go func() {
+				errCh <- r.Run(context.Background())
+			}()
+		}
+		for i := 0; i < 2; i++ {
+			require.ErrorIs(t, <-errCh, assert.AnError)
+		}
+
+		// 4 restarts in total, the first one uses the burst token
+		assert.Equal(t, int32(6), atomic.LoadInt32(&attempts))
+		assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	})
+
+	t.Run("queued restart honors stop", func(t *testing.T) {
+		limiter := NewRestartLimiter(time.Hour, 1)
+		require.NoError(t, limiter.Wait(context.Background()))
+
+		started := make(chan struct{}, 1)
+		r := New(func(ctx context.Context) error {
+			started <- struct{}{}
+			return assert.AnError
+		}, WithRetry(3, ResetNever), WithRestartLimiter(limiter))
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- r.Run(context.Background())
+		}()
+
+		<-started
+
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), 1*time.Second)
+		defer stopCancel()
+		err := r.Stop(stopCtx)
+		require.NoError(t, err)
+		assert.Equal(t, false, r.IsRunning())
+		require.ErrorIs(t, <-errCh, context.Canceled)
+	})
+
+	t.Run("queued restart does not reset the retries", func(t *testing.T) {
+		limiter := NewRestartLimiter(50*time.Millisecond, 1)
+
+		var attempts int32
+		r := New(func(ctx context.Context) error {
+			atomic.AddInt32(&attempts, 1)
+			return assert.AnError
+		}, WithRetry(3, 20*time.Millisecond), WithRestartLimiter(limiter))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.ErrorIs(t, r.Run(ctx), assert.AnError)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	})
+}
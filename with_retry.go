@@ -11,8 +11,6 @@ const ResetNever time.Duration = 0
 type withRetry struct {
 	maxRetries int
 	resetAfter time.Duration
-
-	lastTime time.Time
 }
 
 func WithRetry(maxRetries int, resetAfter time.Duration) Option {
@@ -25,32 +23,39 @@ func WithRetry(maxRetries int, resetAfter time.Duration) Option {
 func (w *withRetry) apply(r *runnable) {
 	runFunc := r.runFunc
 	r.runFunc = func(ctx context.Context) error {
-		var err error
+		var (
+			err error
+			// ran is how long the last attempt ran, the time spent waiting for the
+			// restart limiter excluded
+			ran time.Duration
+		)
 		for i := 0; i < w.maxRetries; i++ {
-			if w.resetAfter != ResetNever && time.Since(w.lastTime) > w.resetAfter {
-				i = 0
-			}
-			w.lastTime = time.Now()
-
 			if i > 0 {
-				if r.onStart != nil {
-					r.onStart()
+				// the previous attempt failed, it is stopped before the next one starts
+				r.stop(err)
+				if r.restartLimiter != nil {
+					if errWait := r.restartLimiter.Wait(r.runScope); errWait != nil {
+						return errWait
+					}
 				}
+				ctx = r.start()
 			}
 
-			err = runFunc(ctx)
+			if w.resetAfter != ResetNever && ran > w.resetAfter {
+				i = 0
+			}
+
+			// the goroutines of the attempt are waited for first, so that the
+			// failure of one of them is retried like the failure of the attempt
+			started := time.Now()
+			err = r.children.wait(runFunc(ctx))
+			ran = time.Since(started)
 			if err == nil {
 				return nil
 			}
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 				return err
 			}
-
-			if i > 0 {
-				if r.onStop != nil {
-					r.onStop()
-				}
-			}
 		}
 		return err
 	}
@@ -3,133 +3,494 @@ package runnable
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type StatusMap map[string]Status
 
+// Status is the status of a runnable in a StatusStore. Its JSON encoding is
+// described by MarshalJSON.
 type Status struct {
-	Running   bool       `json:"running"`
-	Restarts  int        `json:"restarts"`
-	StartTime time.Time  `json:"start_time"`
-	EndTime   *time.Time `json:"end_time,omitempty"`
-	LastError error      `json:"last_error"`
+	Running bool
+	// Restarts is the number of restarts since the process started.
+	Restarts int
+	// TotalRestarts is the number of restarts over all time, including the ones
+	// of previous processes when the store is persisted (see OpenStatusStore).
+	TotalRestarts int
+	StartTime     time.Time
+	EndTime       *time.Time
+	LastError     error
+	// LastErrorTime is the time the last run ended with an error, cancellations included.
+	LastErrorTime *time.Time
+	// ExitReason is the reason the last run ended, or empty if no run ended yet.
+	ExitReason ExitReason
+
+	// Runs is the number of runs that ended. Like the counters below, it carries
+	// on from the loaded snapshot when the store is persisted.
+	Runs         int
+	SuccessCount int
+	// FailureCount is the number of runs that ended with an error other than a
+	// cancellation, panics included.
+	FailureCount    int
+	PanicCount      int
+	LastSuccessTime *time.Time
+	LastFailureTime *time.Time
+	// TotalRunTime is the cumulative time spent running, current run included.
+	TotalRunTime time.Duration
+	// Uptime is the time spent in the current run, or zero if not running.
+	Uptime time.Duration
+	// MeanRunDuration is the mean duration of the runs that ended.
+	MeanRunDuration time.Duration
+
+	History []RunRecord
+
+	// Parent is the ID of the group the runnable runs in, if any (see NewGroupWithOptions).
+	Parent string
+	// Optional is true if the runnable was created with WithOptional.
+	Optional bool
+
+	// Details are the runtime details published with SetDetail.
+	Details map[string]interface{}
+	// Output is the last lines written to Output, oldest first.
+	Output []string
 }
 
-type StatusStore struct {
-	running   map[string]bool
-	restarts  map[string]int
-	startTime map[string]time.Time
-	endTime   map[string]time.Time
-	lastError map[string]error
+type statusCounters struct {
+	runs      int
+	successes int
+	failures  int
+	panics    int
+	runTime   time.Duration
+
+	lastSuccessTime time.Time
+	lastFailureTime time.Time
+}
+
+// statusRecord is the entry of a runnable in a StatusStore.
+type statusRecord struct {
+	running bool
+	// starts is the number of starts since the process started.
+	starts        int
+	totalRestarts int
+	startTime     time.Time
+	endTime       time.Time
+	lastError     error
+	lastErrorTime time.Time
+	exitReason    ExitReason
+	counters      statusCounters
+	history       *ring[RunRecord]
+	parent        string
+	optional      bool
+	details       map[string]interface{}
+	output        *ring[string]
+}
+
+// statusShardCount is the number of shards of a StatusStore. Each shard has its
+// own lock, so that runnables with different IDs rarely contend.
+const statusShardCount = 64
+
+type statusShard struct {
+	records map[string]*statusRecord
+	owners  map[string]interface{}
 
 	mu sync.Mutex
 }
 
-func NewStatusStore() *StatusStore {
-	return &StatusStore{
-		running:   make(map[string]bool),
-		restarts:  make(map[string]int),
-		startTime: make(map[string]time.Time),
-		endTime:   make(map[string]time.Time),
-		lastError: make(map[string]error),
+type StatusStore struct {
+	shards [statusShardCount]statusShard
+
+	historySize int
+	outputLines int
+	clearOutput bool
+	evictAfter  time.Duration
+
+	watchBuffer int
+	watching    atomic.Int32
+	watchers    map[*statusWatcher]struct{}
+	watchersMu  sync.RWMutex
+
+	path            string
+	persistInterval time.Duration
+	dirty           atomic.Bool
+	persistSignal   chan struct{}
+	persistClose    chan struct{}
+	persistDone     chan struct{}
+	closeOnce       sync.Once
+	persistErr      error
+	persistErrMu    sync.Mutex
+}
+
+func NewStatusStore(options ...StatusStoreOption) *StatusStore {
+	s := &StatusStore{
+		historySize: DefaultHistorySize,
+		outputLines: DefaultOutputLines,
+		watchBuffer: DefaultWatchBuffer,
+		watchers:    make(map[*statusWatcher]struct{}),
+
+		persistInterval: DefaultPersistInterval,
+	}
+	for i := range s.shards {
+		s.shards[i].records = make(map[string]*statusRecord)
+		s.shards[i].owners = make(map[string]interface{})
 	}
+
+	for _, option := range options {
+		option.applyStore(s)
+	}
+
+	return s
 }
 
+// Get returns a snapshot of the status of every runnable in the store. Every
+// record is copied, along with its history, details and output, and every shard
+// is locked in turn, so the cost grows with the number of runnables times the
+// history size. Use GetOne to look up a single runnable.
 func (s *StatusStore) Get() StatusMap {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	if s.evictAfter > 0 {
+		s.EvictStale()
+	}
 
 	sm := StatusMap{}
-	for id, running := range s.running {
-		st := Status{
-			Running: running,
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		for id, rec := range shard.records {
+			sm[id] = rec.status()
+		}
+		shard.mu.Unlock()
+	}
+
+	return sm
+}
+
+// GetOne returns the status of the runnable with the given ID, and false if the
+// runnable is not in the store. Unlike Get, it only copies the record of the ID
+// and only locks its shard.
+func (s *StatusStore) GetOne(id string) (Status, bool) {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	rec, ok := shard.records[id]
+	if !ok {
+		return Status{}, false
+	}
+	return rec.status(), true
+}
+
+// shard returns the shard of id, chosen by the FNV-1a hash of id.
+func (s *StatusStore) shard(id string) *statusShard {
+	h := uint32(2166136261)
+	for i := 0; i < len(id); i++ {
+		h ^= uint32(id[i])
+		h *= 16777619
+	}
+	return &s.shards[h%statusShardCount]
+}
+
+// status returns the Status of the record. The shard of the record must be locked.
+func (rec *statusRecord) status() Status {
+	st := Status{
+		Running:       rec.running,
+		TotalRestarts: rec.totalRestarts,
+		StartTime:     rec.startTime,
+		LastError:     rec.lastError,
+		ExitReason:    rec.exitReason,
+		Parent:        rec.parent,
+		Optional:      rec.optional,
+	}
+
+	if rec.starts > 1 {
+		st.Restarts = rec.starts - 1
+	}
+
+	if !rec.endTime.IsZero() {
+		et := rec.endTime
+		st.EndTime = &et
+	}
+
+	if !rec.lastErrorTime.IsZero() {
+		let := rec.lastErrorTime
+		st.LastErrorTime = &let
+	}
+
+	if rec.running {
+		st.Uptime = time.Since(st.StartTime)
+	}
+
+	counters := rec.counters
+	st.Runs = counters.runs
+	st.SuccessCount = counters.successes
+	st.FailureCount = counters.failures
+	st.PanicCount = counters.panics
+	st.TotalRunTime = counters.runTime + st.Uptime
+
+	if !counters.lastSuccessTime.IsZero() {
+		lst := counters.lastSuccessTime
+		st.LastSuccessTime = &lst
+	}
+
+	if !counters.lastFailureTime.IsZero() {
+		lft := counters.lastFailureTime
+		st.LastFailureTime = &lft
+	}
+
+	if counters.runs > 0 {
+		st.MeanRunDuration = counters.runTime / time.Duration(counters.runs)
+	}
+
+	if rec.history != nil {
+		st.History = rec.history.slice()
+	}
+
+	if len(rec.details) > 0 {
+		st.Details = make(map[string]interface{}, len(rec.details))
+		for key, value := range rec.details {
+			st.Details[key] = value
 		}
+	}
 
-		if restarts, ok := s.restarts[id]; ok {
-			st.Restarts = restarts
+	if rec.output != nil {
+		st.Output = rec.output.slice()
+	}
+
+	return st
+}
+
+// Record records a lifecycle event of a runnable. It implements StatusBackend.
+func (s *StatusStore) Record(ev StatusEvent) {
+	switch ev.Type {
+	case StatusEventStarted:
+		s.runStarted(ev.ID, ev.Time, ev.Parent, ev.Optional)
+	case StatusEventEnded:
+		s.runEnded(ev.ID, ev.Time, ev.Err)
+	case StatusEventStopped:
+		s.runStopped(ev.ID, ev.Time)
+	case StatusEventDetail:
+		s.runDetail(ev.ID, ev.Key, ev.Value)
+	case StatusEventOutput:
+		s.runOutput(ev.ID, ev.Line)
+	}
+}
+
+// runStarted records the start of a new run of id, in the given parent group.
+func (s *StatusStore) runStarted(id string, now time.Time, parent string, optional bool) {
+	s.update(id, func(rec *statusRecord) {
+		if !rec.startTime.IsZero() {
+			rec.totalRestarts++
 		}
 
-		if startTime, ok := s.startTime[id]; ok {
-			st.StartTime = startTime
+		rec.running = true
+		rec.startTime = now
+		rec.starts++
+		rec.parent = parent
+		rec.optional = optional
+
+		if s.clearOutput {
+			rec.output = nil
 		}
+	})
+}
 
-		if endTime, ok := s.endTime[id]; ok {
-			et := endTime
-			st.EndTime = &et
+// runStopped records that id is no longer running.
+func (s *StatusStore) runStopped(id string, now time.Time) {
+	s.update(id, func(rec *statusRecord) {
+		rec.running = false
+		rec.endTime = now
+	})
+}
+
+// runEnded records the end of the current run of id.
+func (s *StatusStore) runEnded(id string, now time.Time, err error) {
+	s.update(id, func(rec *statusRecord) {
+		rec.running = false
+		rec.endTime = now
+
+		if err != nil {
+			rec.lastError = err
+			rec.lastErrorTime = now
 		}
+		rec.exitReason = exitReasonOf(err)
 
-		if lastError, ok := s.lastError[id]; ok {
-			st.LastError = lastError
+		rec.counters.update(rec.startTime, now, err)
+
+		if s.historySize > 0 {
+			if rec.history == nil {
+				rec.history = newRing[RunRecord](s.historySize)
+			}
+			rec.history.push(newRunRecord(rec.startTime, now, err))
 		}
+	})
+}
+
+func (c *statusCounters) update(startTime, endTime time.Time, err error) {
+	c.runs++
+	c.runTime += endTime.Sub(startTime)
 
-		sm[id] = st
+	switch exitReasonOf(err) {
+	case ExitReasonSuccess:
+		c.successes++
+		c.lastSuccessTime = endTime
+	case ExitReasonPanic:
+		c.panics++
+		c.failures++
+		c.lastFailureTime = endTime
+	case ExitReasonError:
+		c.failures++
+		c.lastFailureTime = endTime
 	}
+}
 
-	return sm
+// update applies fn to the record of id, creating it if needed, notifies the
+// watchers of the change and schedules a snapshot if the store is persisted. Only
+// the shard of id is locked.
+func (s *StatusStore) update(id string, fn func(rec *statusRecord)) {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	rec, ok := shard.records[id]
+	if !ok {
+		rec = &statusRecord{}
+		shard.records[id] = rec
+	}
+
+	if s.watching.Load() == 0 {
+		fn(rec)
+	} else {
+		var oldStatus Status
+		if ok {
+			oldStatus = rec.status()
+		}
+		fn(rec)
+
+		s.notify(StatusChange{ID: id, Old: oldStatus, New: rec.status()})
+	}
+	shard.mu.Unlock()
+
+	s.markDirty()
+}
+
+// notify pushes change to the watchers. It is called with the shard of the
+// changed ID locked, so that the changes of an ID are delivered in order.
+func (s *StatusStore) notify(change StatusChange) {
+	s.watchersMu.RLock()
+	defer s.watchersMu.RUnlock()
+
+	for watcher := range s.watchers {
+		watcher.push(change)
+	}
 }
 
 type withStatus struct {
 	runnableID string
-	store      *StatusStore
+	backend    StatusBackend
+
+	owner *runnable
+
+	// key and parent are resolved by beforeRun, for each run
+	key    string
+	parent string
+	// output is the Output of the current run
+	output *outputWriter
+}
+
+// attemptStarted records the start of an attempt. Every attempt of the run, see
+// WithRetry, is recorded as a run of its own.
+func (w *withStatus) attemptStarted() {
+	w.record(StatusEventStarted, nil)
 }
 
-func (w *withStatus) apply(r *runnable) {
-	runFuncRunnable := r.runFunc
-	onStartRunnable := r.onStart
-	onStopRunnable := r.onStop
+// attemptStopped records the end of an attempt.
+func (w *withStatus) attemptStopped(err error) {
+	w.record(StatusEventEnded, err)
+	w.record(StatusEventStopped, nil)
+}
 
-	r.runFunc = func(ctx context.Context) error {
-		defer func() {
-			w.store.mu.Lock()
-			w.store.running[w.runnableID] = false
-			w.store.endTime[w.runnableID] = time.Now()
-			w.store.mu.Unlock()
-		}()
+// beforeRun resolves the key of the runnable, nested under the key of its group
+// if any, claims it if the backend is a StatusClaimer, and stores the status
+// scope in the run context.
+func (w *withStatus) beforeRun(ctx context.Context) (context.Context, error) {
+	w.key, w.parent = statusKey(ctx, w.runnableID)
 
-		err := runFuncRunnable(ctx)
-		if err != nil {
-			w.store.mu.Lock()
-			w.store.lastError[w.runnableID] = err
-			w.store.mu.Unlock()
-			return err
+	if claimer, ok := w.backend.(StatusClaimer); ok {
+		if err := claimer.Claim(w.key, w.owner); err != nil {
+			return ctx, err
 		}
-		return nil
 	}
 
-	r.onStart = func() {
-		w.store.mu.Lock()
+	w.output = &outputWriter{backend: w.backend, key: w.key}
+	return context.WithValue(ctx, statusScopeKey{}, &statusScope{
+		owner:   w.owner,
+		backend: w.backend,
+		key:     w.key,
+		output:  w.output,
+	}), nil
+}
 
-		w.store.running[w.runnableID] = true
-		w.store.startTime[w.runnableID] = time.Now()
-		if _, ok := w.store.restarts[w.runnableID]; !ok {
-			w.store.restarts[w.runnableID] = 0
-		} else {
-			w.store.restarts[w.runnableID]++
-		}
+// afterRun records the unterminated last line of the output and releases the key
+// claimed by beforeRun.
+func (w *withStatus) afterRun(ctx context.Context, err error) {
+	w.output.flush()
 
-		w.store.mu.Unlock()
+	if claimer, ok := w.backend.(StatusClaimer); ok {
+		claimer.Release(w.key, w.owner)
+	}
+}
 
-		if onStartRunnable != nil {
-			onStartRunnable()
-		}
+// statusKey returns the key of the runnable with the given ID, nested under the
+// key of the group it runs in with ctx, if any, and the key of the group.
+func statusKey(ctx context.Context, id string) (key string, parent string) {
+	if prefix, ok := ctx.Value(statusPrefixKey{}).(string); ok && prefix != "" {
+		return prefix + "/" + id, prefix
 	}
+	return id, ""
+}
 
-	r.onStop = func() {
-		w.store.mu.Lock()
-		w.store.running[w.runnableID] = false
-		w.store.endTime[w.runnableID] = time.Now()
-		w.store.mu.Unlock()
+type statusScopeKey struct{}
 
-		if onStopRunnable != nil {
-			onStopRunnable()
-		}
-	}
+// statusScope describes the status of the run in progress; it is stored in the run context.
+type statusScope struct {
+	owner   *runnable
+	backend StatusBackend
+	key     string
+	output  *outputWriter
 }
 
-func WithStatus(id string, store *StatusStore) Option {
-	return &withStatus{
-		runnableID: id,
-		store:      store,
+func (w *withStatus) record(typ StatusEventType, err error) {
+	ev := StatusEvent{
+		ID:   w.key,
+		Type: typ,
+		Time: time.Now(),
+		Err:  err,
 	}
+	if typ == StatusEventStarted {
+		ev.Parent = w.parent
+		ev.Optional = w.owner.optional
+	}
+	w.backend.Record(ev)
+}
+
+// WithStatus records the lifecycle of the runnable under the given ID in backend,
+// usually a *StatusStore.
+//
+// Example:
+//
+//	store := runnable.NewStatusStore()
+//	r := runnable.New(run, runnable.WithStatus("monitor", store))
+func WithStatus(id string, backend StatusBackend) Option {
+	return optionFunc(func(r *runnable) {
+		w := &withStatus{
+			runnableID: id,
+			backend:    backend,
+			owner:      r,
+			key:        id,
+		}
+
+		// the first ID is the ID of the runnable
+		if r.statusID == "" {
+			r.id, r.statusID = id, id
+		}
+		r.runHooks = append(r.runHooks, w)
+		r.chainHooks(w.attemptStarted, w.attemptStopped)
+	})
 }
@@ -0,0 +1,186 @@
+package runnable
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// StatusBackend receives the lifecycle events of the runnables wrapped with
+// WithStatus. StatusStore is the in-memory implementation; JSONLBackend and
+// FanOutBackend are also provided. Implementations must be safe for concurrent use.
+type StatusBackend interface {
+	Record(ev StatusEvent)
+}
+
+// StatusEventType is the type of a StatusEvent.
+type StatusEventType string
+
+const (
+	// StatusEventStarted is recorded when a run starts, including restarts.
+	StatusEventStarted StatusEventType = "started"
+	// StatusEventEnded is recorded when runFunc returns, with its error.
+	StatusEventEnded StatusEventType = "ended"
+	// StatusEventStopped is recorded when the runnable stops running.
+	StatusEventStopped StatusEventType = "stopped"
+	// StatusEventDetail is recorded when the runnable publishes a detail with SetDetail.
+	StatusEventDetail StatusEventType = "detail"
+	// StatusEventOutput is recorded when the runnable writes a line to its Output.
+	StatusEventOutput StatusEventType = "output"
+)
+
+// StatusEvent is a lifecycle event of a runnable.
+type StatusEvent struct {
+	ID   string
+	Type StatusEventType
+	Time time.Time
+	Err  error
+
+	// Parent and Optional are set on started events, see Status.
+	Parent   string
+	Optional bool
+
+	// Key and Value are set on detail events, see SetDetail.
+	Key   string
+	Value interface{}
+
+	// Line is set on output events, see Output.
+	Line string
+}
+
+type statusEventJSON struct {
+	Time  time.Time       `json:"time"`
+	ID    string          `json:"id"`
+	Type  StatusEventType `json:"type"`
+	Error *StatusError    `json:"error,omitempty"`
+
+	Parent   string `json:"parent,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+
+	Key   string      `json:"key,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+
+	Line string `json:"line,omitempty"`
+}
+
+// MarshalJSON encodes the event with Err as its message and type. A Value that
+// cannot be encoded is replaced by its error.
+func (ev StatusEvent) MarshalJSON() ([]byte, error) {
+	ej := statusEventJSON{
+		Time:  ev.Time,
+		ID:    ev.ID,
+		Type:  ev.Type,
+		Error: newStatusError(ev.Err),
+
+		Parent:   ev.Parent,
+		Optional: ev.Optional,
+
+		Key: ev.Key,
+
+		Line: ev.Line,
+	}
+	if ev.Value != nil {
+		ej.Value = encodeDetail(ev.Value)
+	}
+	return json.Marshal(ej)
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON. Err, if any, is decoded
+// as a *StatusError.
+func (ev *StatusEvent) UnmarshalJSON(data []byte) error {
+	var ej statusEventJSON
+	if err := json.Unmarshal(data, &ej); err != nil {
+		return err
+	}
+
+	*ev = StatusEvent{
+		ID:   ej.ID,
+		Type: ej.Type,
+		Time: ej.Time,
+
+		Parent:   ej.Parent,
+		Optional: ej.Optional,
+
+		Key:   ej.Key,
+		Value: ej.Value,
+
+		Line: ej.Line,
+	}
+	if ej.Error != nil {
+		ev.Err = ej.Error
+	}
+	return nil
+}
+
+var _ StatusBackend = (*StatusStore)(nil)
+
+// FanOutBackend is a StatusBackend that records every event in several backends, in order.
+type FanOutBackend struct {
+	backends []StatusBackend
+}
+
+var _ StatusBackend = (*FanOutBackend)(nil)
+
+// NewFanOutBackend creates a new FanOutBackend that records events in all the given backends.
+//
+// Example:
+//
+//	store := runnable.NewStatusStore()
+//	events, err := runnable.OpenJSONLBackend("/var/log/app/runnables.jsonl")
+//	if err != nil {
+//		return err
+//	}
+//
+//	r := runnable.New(run, runnable.WithStatus("monitor", runnable.NewFanOutBackend(store, events)))
+func NewFanOutBackend(backends ...StatusBackend) *FanOutBackend {
+	return &FanOutBackend{
+		backends: backends,
+	}
+}
+
+func (f *FanOutBackend) Record(ev StatusEvent) {
+	for _, backend := range f.backends {
+		backend.Record(ev)
+	}
+}
+
+// JSONLBackend is a StatusBackend that appends every event as a line of JSON to
+// an io.Writer.
+type JSONLBackend struct {
+	w *jsonlWriter
+}
+
+var _ StatusBackend = (*JSONLBackend)(nil)
+
+// NewJSONLBackend creates a new JSONLBackend that writes to w.
+func NewJSONLBackend(w io.Writer) *JSONLBackend {
+	return &JSONLBackend{
+		w: newJSONLWriter(w),
+	}
+}
+
+// OpenJSONLBackend creates a new JSONLBackend that appends to the file at path,
+// creating it if needed. The file is closed by Close.
+func OpenJSONLBackend(path string) (*JSONLBackend, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONLBackend(file), nil
+}
+
+func (j *JSONLBackend) Record(ev StatusEvent) {
+	j.w.write(ev)
+}
+
+// Err returns the last error that occurred while writing an event, if any, or
+// the error reported by the Err method of the writer, e.g. of a RotatingFile.
+func (j *JSONLBackend) Err() error {
+	return j.w.lastErr()
+}
+
+// Close closes the underlying writer, if it is an io.Closer.
+func (j *JSONLBackend) Close() error {
+	return j.w.close()
+}
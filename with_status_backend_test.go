@@ -0,0 +1,76 @@
+package runnable
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusBackend(t *testing.T) {
+
+	t.Run("jsonl backend", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+
+		backend, err := OpenJSONLBackend(path)
+		require.NoError(t, err)
+
+		r := New(func(ctx context.Context) error {
+			return assert.AnError
+		}, WithStatus("test", backend))
+		require.Error(t, r.Run(context.Background()))
+		require.NoError(t, backend.Err())
+		require.NoError(t, backend.Close())
+
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		var events []StatusEvent
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var ev StatusEvent
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+			events = append(events, ev)
+		}
+		require.NoError(t, scanner.Err())
+
+		require.Len(t, events, 3)
+		assert.Equal(t, StatusEventStarted, events[0].Type)
+		assert.Equal(t, StatusEventEnded, events[1].Type)
+		assert.Equal(t, StatusEventStopped, events[2].Type)
+		assert.Equal(t, "test", events[1].ID)
+		assert.Equal(t, assert.AnError.Error(), events[1].Err.Error())
+		assert.False(t, events[1].Time.IsZero())
+	})
+
+	t.Run("fan out backend", func(t *testing.T) {
+		store1, store2 := NewStatusStore(), NewStatusStore()
+		recorder := &recordingBackend{}
+
+		r := New(func(ctx context.Context) error {
+			return assert.AnError
+		}, WithStatus("test", NewFanOutBackend(store1, store2, recorder)))
+		require.Error(t, r.Run(context.Background()))
+
+		for _, store := range []*StatusStore{store1, store2} {
+			s := store.Get()["test"]
+			assert.Equal(t, false, s.Running)
+			assert.Equal(t, assert.AnError, s.LastError)
+		}
+		assert.Len(t, recorder.events, 3)
+	})
+}
+
+type recordingBackend struct {
+	events []StatusEvent
+}
+
+func (r *recordingBackend) Record(ev StatusEvent) {
+	r.events = append(r.events, ev)
+}
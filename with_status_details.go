@@ -0,0 +1,54 @@
+package runnable
+
+import (
+	"context"
+	"time"
+)
+
+// SetDetail publishes a runtime detail of the running runnable, e.g. the block
+// height or queue depth of a worker, in the Details of its Status. It is recorded
+// by the nearest runnable wrapped with WithStatus whose run context is ctx or a
+// parent of ctx, and does nothing otherwise. A nil value removes the detail.
+//
+// Details are kept across runs. Values should not be modified after they are set,
+// and should be encodable as JSON; a value that is not, e.g. NaN or a func, is
+// encoded as its error.
+//
+// Example:
+//
+//	func (w *Worker) run(ctx context.Context) error {
+//		for block := range w.blocks(ctx) {
+//			w.process(block)
+//			runnable.SetDetail(ctx, "block_height", block.Number)
+//		}
+//		return nil
+//	}
+func SetDetail(ctx context.Context, key string, value interface{}) {
+	scope, ok := ctx.Value(statusScopeKey{}).(*statusScope)
+	if !ok {
+		return
+	}
+
+	scope.backend.Record(StatusEvent{
+		ID:    scope.key,
+		Type:  StatusEventDetail,
+		Time:  time.Now(),
+		Key:   key,
+		Value: value,
+	})
+}
+
+// runDetail records the detail key of id.
+func (s *StatusStore) runDetail(id string, key string, value interface{}) {
+	s.update(id, func(rec *statusRecord) {
+		if value == nil {
+			delete(rec.details, key)
+			return
+		}
+
+		if rec.details == nil {
+			rec.details = make(map[string]interface{})
+		}
+		rec.details[key] = value
+	})
+}
@@ -0,0 +1,116 @@
+package runnable
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDetail(t *testing.T) {
+
+	t.Run("details", func(t *testing.T) {
+		store := NewStatusStore()
+
+		r := New(func(ctx context.Context) error {
+			SetDetail(ctx, "block_height", 42)
+			SetDetail(ctx, "cursor", "abc")
+			SetDetail(ctx, "queue_depth", 3)
+			SetDetail(ctx, "queue_depth", nil)
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		st := store.Get()["test"]
+		assert.Equal(t, map[string]interface{}{"block_height": 42, "cursor": "abc"}, st.Details)
+
+		st.Details["cursor"] = "modified"
+		assert.Equal(t, "abc", store.Get()["test"].Details["cursor"])
+
+		data, err := json.Marshal(store.Get())
+		require.NoError(t, err)
+
+		var sm StatusMap
+		require.NoError(t, json.Unmarshal(data, &sm))
+		assert.Equal(t, map[string]interface{}{"block_height": float64(42), "cursor": "abc"}, sm["test"].Details)
+	})
+
+	t.Run("unencodable values", func(t *testing.T) {
+		store := NewStatusStore()
+		var buf bytes.Buffer
+		events := NewJSONLBackend(&buf)
+
+		r := New(func(ctx context.Context) error {
+			SetDetail(ctx, "rate", math.NaN())
+			SetDetail(ctx, "callback", func() {})
+			SetDetail(ctx, "cursor", "abc")
+			return nil
+		}, WithStatus("test", NewFanOutBackend(store, events)))
+		require.NoError(t, r.Run(context.Background()))
+
+		data, err := json.Marshal(store.Get())
+		require.NoError(t, err)
+
+		var sm StatusMap
+		require.NoError(t, json.Unmarshal(data, &sm))
+		details := sm["test"].Details
+		assert.Equal(t, "abc", details["cursor"])
+		assert.Equal(t, "error: json: unsupported value: NaN", details["rate"])
+		assert.Contains(t, details["callback"], "json: unsupported type")
+
+		require.NoError(t, events.Err())
+		assert.Contains(t, buf.String(), `"value":"error: json: unsupported value: NaN"`)
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		store := NewStatusStore()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		changes := store.Watch(ctx)
+
+		r := New(func(ctx context.Context) error {
+			SetDetail(ctx, "progress", 0.5)
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		<-changes
+		change := <-changes
+		assert.Nil(t, change.Old.Details)
+		assert.Equal(t, 0.5, change.New.Details["progress"])
+	})
+
+	t.Run("group", func(t *testing.T) {
+		store := NewStatusStore()
+
+		group := NewGroupWithOptions([]Runnable{
+			New(func(ctx context.Context) error {
+				SetDetail(ctx, "member", "anonymous")
+				return nil
+			}),
+			New(func(ctx context.Context) error {
+				SetDetail(ctx, "member", "worker")
+				return nil
+			}, WithStatus("worker", store)),
+		}, WithStatus("api", store))
+		require.NoError(t, group.Run(context.Background()))
+
+		sm := store.Get()
+		assert.Equal(t, "anonymous", sm["api"].Details["member"])
+		assert.Equal(t, "worker", sm["api/worker"].Details["member"])
+	})
+
+	t.Run("without status", func(t *testing.T) {
+		r := New(func(ctx context.Context) error {
+			SetDetail(ctx, "key", "value")
+			return nil
+		})
+		require.NoError(t, r.Run(context.Background()))
+		SetDetail(context.Background(), "key", "value")
+	})
+}
@@ -0,0 +1,91 @@
+package runnable
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultHistorySize is the number of runs a StatusStore keeps per ID by default.
+const DefaultHistorySize = 10
+
+// ExitReason describes why a run ended.
+type ExitReason string
+
+const (
+	// ExitReasonSuccess means runFunc returned nil.
+	ExitReasonSuccess ExitReason = "success"
+	// ExitReasonError means runFunc returned an error.
+	ExitReasonError ExitReason = "error"
+	// ExitReasonCanceled means runFunc returned because its context was cancelled,
+	// e.g. by Stop, or its deadline was exceeded.
+	ExitReasonCanceled ExitReason = "canceled"
+	// ExitReasonPanic means runFunc panicked.
+	ExitReasonPanic ExitReason = "panic"
+)
+
+// errUnrecoveredPanic is recorded as the error of runs that panicked without
+// being recovered by WithRecoverer.
+var errUnrecoveredPanic = errors.New("unrecovered panic")
+
+// RunRecord describes a past run of a runnable.
+type RunRecord struct {
+	StartTime  time.Time
+	EndTime    time.Time
+	Duration   time.Duration
+	ExitReason ExitReason
+	Error      error
+}
+
+func newRunRecord(startTime, endTime time.Time, err error) RunRecord {
+	return RunRecord{
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Duration:   endTime.Sub(startTime),
+		ExitReason: exitReasonOf(err),
+		Error:      err,
+	}
+}
+
+func exitReasonOf(err error) ExitReason {
+	var panicErr *PanicError
+	switch {
+	case err == nil:
+		return ExitReasonSuccess
+	case errors.As(err, &panicErr) || errors.Is(err, errUnrecoveredPanic):
+		return ExitReasonPanic
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return ExitReasonCanceled
+	default:
+		return ExitReasonError
+	}
+}
+
+// StatusStoreOption configures a StatusStore.
+type StatusStoreOption interface {
+	applyStore(*StatusStore)
+}
+
+type historySize int
+
+// WithHistorySize sets the number of past runs the store keeps per ID. The default
+// is DefaultHistorySize; zero disables the history.
+func WithHistorySize(size int) StatusStoreOption {
+	return historySize(size)
+}
+
+func (h historySize) applyStore(s *StatusStore) {
+	s.historySize = int(h)
+}
+
+// History returns the past runs of the runnable with the given ID, oldest first.
+func (s *StatusStore) History(id string) []RunRecord {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if rec, ok := shard.records[id]; ok && rec.history != nil {
+		return rec.history.slice()
+	}
+	return nil
+}
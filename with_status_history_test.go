@@ -0,0 +1,94 @@
+package runnable
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusHistory(t *testing.T) {
+
+	t.Run("history", func(t *testing.T) {
+		store := NewStatusStore()
+
+		counter := 0
+		r := New(func(ctx context.Context) error {
+			defer func() { counter++ }()
+			switch counter {
+			case 0:
+				return assert.AnError
+			case 1:
+				panic("something went wrong")
+			case 2:
+				return context.Canceled
+			}
+			return nil
+		}, WithRecoverer(nil, nil), WithStatus("test", store), WithRetry(5, ResetNever))
+
+		require.ErrorIs(t, r.Run(context.Background()), context.Canceled)
+		require.NoError(t, r.Run(context.Background()))
+
+		history := store.History("test")
+		require.Len(t, history, 4)
+		assert.Equal(t, ExitReasonError, history[0].ExitReason)
+		assert.Equal(t, assert.AnError, history[0].Error)
+		assert.Equal(t, ExitReasonPanic, history[1].ExitReason)
+		assert.Equal(t, ExitReasonCanceled, history[2].ExitReason)
+		assert.Equal(t, ExitReasonSuccess, history[3].ExitReason)
+		assert.Nil(t, history[3].Error)
+
+		for _, record := range history {
+			assert.False(t, record.StartTime.IsZero())
+			assert.Equal(t, record.EndTime.Sub(record.StartTime), record.Duration)
+		}
+
+		assert.Equal(t, history, store.Get()["test"].History)
+		assert.Nil(t, store.History("unknown"))
+	})
+
+	t.Run("history size", func(t *testing.T) {
+		store := NewStatusStore(WithHistorySize(2))
+
+		r := New(func(ctx context.Context) error {
+			return assert.AnError
+		}, WithStatus("test", store), WithRetry(5, ResetNever))
+		require.Error(t, r.Run(context.Background()))
+
+		assert.Len(t, store.History("test"), 2)
+	})
+
+	t.Run("history disabled", func(t *testing.T) {
+		store := NewStatusStore(WithHistorySize(0))
+
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Empty(t, store.History("test"))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		store := NewStatusStore()
+
+		r := New(func(ctx context.Context) error {
+			return assert.AnError
+		}, WithStatus("test", store))
+		require.Error(t, r.Run(context.Background()))
+
+		data, err := json.Marshal(store.Get())
+		require.NoError(t, err)
+
+		var decoded StatusMap
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		history := decoded["test"].History
+		require.Len(t, history, 1)
+		assert.Equal(t, ExitReasonError, history[0].ExitReason)
+		assert.Equal(t, assert.AnError.Error(), history[0].Error.Error())
+		assert.True(t, history[0].EndTime.Equal(store.History("test")[0].EndTime))
+	})
+}
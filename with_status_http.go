@@ -0,0 +1,141 @@
+package runnable
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// HandlerOption configures the http.Handler returned by StatusStore.Handler.
+type HandlerOption interface {
+	applyHandler(*statusHandler)
+}
+
+type criticalIDs []string
+
+// WithCriticalIDs sets the IDs of the runnables /healthz and /readyz report on. By
+// default every runnable known to the store is critical.
+func WithCriticalIDs(ids ...string) HandlerOption {
+	return criticalIDs(ids)
+}
+
+func (c criticalIDs) applyHandler(h *statusHandler) {
+	h.criticalIDs = append(h.criticalIDs, c...)
+}
+
+type statusHandler struct {
+	store       *StatusStore
+	criticalIDs []string
+}
+
+// HealthResponse is the body served by the /healthz and /readyz routes.
+type HealthResponse struct {
+	Status  string   `json:"status"`
+	Failing []string `json:"failing,omitempty"`
+}
+
+// Handler returns an http.Handler that serves the status of the store as JSON:
+//
+//   - GET /             the full StatusMap
+//   - GET /tree         the runnables as a tree of StatusNode, see StatusStore.Tree
+//   - GET /status/{id}  the Status of a single runnable, or 404 if it is unknown
+//   - GET /healthz      200 unless a critical runnable has stopped after an error
+//     or a panic, 503 otherwise
+//   - GET /readyz       200 if every critical runnable is running, 503 otherwise
+//
+// A critical runnable that has not started yet is healthy but not ready. One that
+// returned nil or was canceled, e.g. by Stop, is healthy as it exited cleanly.
+//
+// Use http.StripPrefix to mount it under a path.
+//
+// Example:
+//
+//	store := runnable.NewStatusStore()
+//	mux := http.NewServeMux()
+//	mux.Handle("/runnables/", http.StripPrefix("/runnables", store.Handler(runnable.WithCriticalIDs("api", "worker"))))
+func (s *StatusStore) Handler(options ...HandlerOption) http.Handler {
+	h := &statusHandler{
+		store: s,
+	}
+
+	for _, option := range options {
+		option.applyHandler(h)
+	}
+
+	return h
+}
+
+func (h *statusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	if id, ok := strings.CutPrefix(path, "status/"); ok {
+		st, ok := h.store.GetOne(id)
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, st)
+		return
+	}
+
+	switch path {
+	case "":
+		writeJSON(w, http.StatusOK, h.store.Get())
+	case "tree":
+		writeJSON(w, http.StatusOK, h.store.Tree())
+	case "healthz":
+		h.serveHealth(w, func(st Status, ok bool) bool {
+			return !ok || st.Running || exitedCleanly(st)
+		})
+	case "readyz":
+		h.serveHealth(w, func(st Status, ok bool) bool {
+			return ok && st.Running
+		})
+	default:
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	}
+}
+
+func (h *statusHandler) serveHealth(w http.ResponseWriter, healthy func(st Status, ok bool) bool) {
+	sm := h.store.Get()
+
+	ids := h.criticalIDs
+	if len(ids) == 0 {
+		for id := range sm {
+			ids = append(ids, id)
+		}
+	}
+
+	var failing []string
+	for _, id := range ids {
+		st, ok := sm[id]
+		if !healthy(st, ok) {
+			failing = append(failing, id)
+		}
+	}
+	sort.Strings(failing)
+
+	if len(failing) > 0 {
+		writeJSON(w, http.StatusServiceUnavailable, HealthResponse{Status: "unavailable", Failing: failing})
+		return
+	}
+	writeJSON(w, http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
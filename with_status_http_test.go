@@ -0,0 +1,152 @@
+package runnable
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusHandler(t *testing.T) {
+	store := NewStatusStore()
+
+	started := make(chan struct{})
+	running := New(func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil
+	}, WithStatus("running", store))
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- running.Run(context.Background())
+	}()
+	<-started
+	defer func() {
+		require.NoError(t, running.Stop(context.Background()))
+		require.NoError(t, <-errCh)
+	}()
+
+	failed := New(func(ctx context.Context) error {
+		return assert.AnError
+	}, WithStatus("failed", store))
+	require.Error(t, failed.Run(context.Background()))
+
+	get := func(h http.Handler, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("status map", func(t *testing.T) {
+		rec := get(store.Handler(), "/")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var sm StatusMap
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sm))
+		assert.Equal(t, true, sm["running"].Running)
+		assert.Equal(t, false, sm["failed"].Running)
+		assert.Equal(t, assert.AnError.Error(), sm["failed"].LastError.Error())
+	})
+
+	t.Run("status by id", func(t *testing.T) {
+		rec := get(store.Handler(), "/status/failed")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var st Status
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &st))
+		assert.Equal(t, false, st.Running)
+		assert.Equal(t, ExitReasonError, st.ExitReason)
+
+		rec = get(store.Handler(), "/status/unknown")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = get(store.Handler(), "/failed")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("status by reserved id", func(t *testing.T) {
+		store := NewStatusStore()
+
+		for _, id := range []string{"tree", "healthz", "readyz", "api/worker"} {
+			r := New(func(ctx context.Context) error {
+				return nil
+			}, WithStatus(id, store))
+			require.NoError(t, r.Run(context.Background()))
+
+			rec := get(store.Handler(), "/status/"+id)
+			assert.Equal(t, http.StatusOK, rec.Code, id)
+		}
+	})
+
+	t.Run("healthz", func(t *testing.T) {
+		rec := get(store.Handler(), "/healthz")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+		var resp HealthResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"failed"}, resp.Failing)
+
+		rec = get(store.Handler(WithCriticalIDs("running", "not-started")), "/healthz")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("healthz, clean exit", func(t *testing.T) {
+		store := NewStatusStore()
+
+		done := New(func(ctx context.Context) error {
+			return nil
+		}, WithStatus("done", store))
+		require.NoError(t, done.Run(context.Background()))
+
+		rec := get(store.Handler(), "/healthz")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = get(store.Handler(), "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+
+	t.Run("readyz", func(t *testing.T) {
+		rec := get(store.Handler(WithCriticalIDs("running")), "/readyz")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = get(store.Handler(WithCriticalIDs("running", "not-started")), "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+		var resp HealthResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, []string{"not-started"}, resp.Failing)
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		rec := get(store.Handler(), "/tree")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var nodes []*StatusNode
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &nodes))
+		require.Len(t, nodes, 2)
+		assert.Equal(t, "failed", nodes[0].ID)
+		assert.Equal(t, HealthDown, nodes[0].Health)
+		assert.Equal(t, "running", nodes[1].ID)
+		assert.Equal(t, HealthHealthy, nodes[1].Health)
+	})
+
+	t.Run("strip prefix", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.Handle("/runnables/", http.StripPrefix("/runnables", store.Handler()))
+
+		rec := get(mux, "/runnables/status/running")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		store.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
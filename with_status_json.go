@@ -0,0 +1,256 @@
+package runnable
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// StatusSchemaVersion is the version of the JSON encoding of StatusMap. It is
+// incremented whenever a change to the encoding is not backwards compatible.
+const StatusSchemaVersion = 1
+
+// StatusError is the type of Status.LastError once a Status has been decoded from JSON.
+type StatusError struct {
+	// Message is the error message.
+	Message string `json:"message"`
+	// Type is the Go type of the original error, e.g. "*errors.errorString".
+	Type string `json:"type"`
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+type statusJSON struct {
+	Running       bool         `json:"running"`
+	Restarts      int          `json:"restarts"`
+	TotalRestarts int          `json:"total_restarts"`
+	StartTime     time.Time    `json:"start_time"`
+	EndTime       *time.Time   `json:"end_time,omitempty"`
+	UptimeSeconds float64      `json:"uptime_seconds,omitempty"`
+	LastError     *StatusError `json:"last_error,omitempty"`
+	LastErrorTime *time.Time   `json:"last_error_time,omitempty"`
+	ExitReason    ExitReason   `json:"exit_reason,omitempty"`
+
+	Runs                   int         `json:"runs"`
+	SuccessCount           int         `json:"success_count"`
+	FailureCount           int         `json:"failure_count"`
+	PanicCount             int         `json:"panic_count"`
+	LastSuccessTime        *time.Time  `json:"last_success_time,omitempty"`
+	LastFailureTime        *time.Time  `json:"last_failure_time,omitempty"`
+	TotalRunTimeSeconds    float64     `json:"total_run_time_seconds"`
+	MeanRunDurationSeconds float64     `json:"mean_run_duration_seconds"`
+	History                []RunRecord `json:"history,omitempty"`
+
+	Parent   string `json:"parent,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+
+	Details map[string]interface{} `json:"details,omitempty"`
+	Output  []string               `json:"output,omitempty"`
+}
+
+type runRecordJSON struct {
+	StartTime       time.Time    `json:"start_time"`
+	EndTime         time.Time    `json:"end_time"`
+	DurationSeconds float64      `json:"duration_seconds"`
+	ExitReason      ExitReason   `json:"exit_reason"`
+	Error           *StatusError `json:"error,omitempty"`
+}
+
+type statusMapJSON struct {
+	Version   int               `json:"version"`
+	Runnables map[string]Status `json:"runnables"`
+}
+
+// MarshalJSON encodes the status with LastError as its message and type, and
+// durations in seconds. The uptime of a running runnable is derived from its
+// start time when Uptime is not set.
+func (s Status) MarshalJSON() ([]byte, error) {
+	sj := statusJSON{
+		Running:       s.Running,
+		Restarts:      s.Restarts,
+		TotalRestarts: s.TotalRestarts,
+		StartTime:     s.StartTime,
+		EndTime:       s.EndTime,
+		UptimeSeconds: s.Uptime.Seconds(),
+		LastError:     newStatusError(s.LastError),
+		LastErrorTime: s.LastErrorTime,
+		ExitReason:    s.ExitReason,
+
+		Runs:                   s.Runs,
+		SuccessCount:           s.SuccessCount,
+		FailureCount:           s.FailureCount,
+		PanicCount:             s.PanicCount,
+		LastSuccessTime:        s.LastSuccessTime,
+		LastFailureTime:        s.LastFailureTime,
+		TotalRunTimeSeconds:    s.TotalRunTime.Seconds(),
+		MeanRunDurationSeconds: s.MeanRunDuration.Seconds(),
+		History:                s.History,
+
+		Parent:   s.Parent,
+		Optional: s.Optional,
+
+		Details: encodeDetails(s.Details),
+		Output:  s.Output,
+	}
+
+	if s.Uptime == 0 && s.Running && !s.StartTime.IsZero() {
+		sj.UptimeSeconds = time.Since(s.StartTime).Seconds()
+	}
+
+	return json.Marshal(sj)
+}
+
+// encodeDetails encodes each detail on its own, so that a value that cannot be
+// encoded, e.g. NaN or a func, is replaced by its error instead of failing the
+// encoding of the whole status.
+func encodeDetails(details map[string]interface{}) map[string]interface{} {
+	if len(details) == 0 {
+		return nil
+	}
+
+	encoded := make(map[string]interface{}, len(details))
+	for key, value := range details {
+		encoded[key] = encodeDetail(value)
+	}
+	return encoded
+}
+
+// encodeDetail encodes value, or returns its error as a string if it cannot be encoded.
+func encodeDetail(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return json.RawMessage(data)
+}
+
+// UnmarshalJSON decodes a status encoded by MarshalJSON. LastError, if any, is
+// decoded as a *StatusError.
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var sj statusJSON
+	if err := json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+
+	*s = Status{
+		Running:       sj.Running,
+		Restarts:      sj.Restarts,
+		TotalRestarts: sj.TotalRestarts,
+		StartTime:     sj.StartTime,
+		EndTime:       sj.EndTime,
+		Uptime:        seconds(sj.UptimeSeconds),
+		LastErrorTime: sj.LastErrorTime,
+		ExitReason:    sj.ExitReason,
+
+		Runs:            sj.Runs,
+		SuccessCount:    sj.SuccessCount,
+		FailureCount:    sj.FailureCount,
+		PanicCount:      sj.PanicCount,
+		LastSuccessTime: sj.LastSuccessTime,
+		LastFailureTime: sj.LastFailureTime,
+		TotalRunTime:    seconds(sj.TotalRunTimeSeconds),
+		MeanRunDuration: seconds(sj.MeanRunDurationSeconds),
+		History:         sj.History,
+
+		Parent:   sj.Parent,
+		Optional: sj.Optional,
+
+		Details: sj.Details,
+		Output:  sj.Output,
+	}
+	if sj.LastError != nil {
+		s.LastError = sj.LastError
+	}
+	return nil
+}
+
+// MarshalJSON encodes the run record with Error as its message and type.
+func (rr RunRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(runRecordJSON{
+		StartTime:       rr.StartTime,
+		EndTime:         rr.EndTime,
+		DurationSeconds: rr.Duration.Seconds(),
+		ExitReason:      rr.ExitReason,
+		Error:           newStatusError(rr.Error),
+	})
+}
+
+// UnmarshalJSON decodes a run record encoded by MarshalJSON. Error, if any, is
+// decoded as a *StatusError.
+func (rr *RunRecord) UnmarshalJSON(data []byte) error {
+	var rj runRecordJSON
+	if err := json.Unmarshal(data, &rj); err != nil {
+		return err
+	}
+
+	*rr = RunRecord{
+		StartTime:  rj.StartTime,
+		EndTime:    rj.EndTime,
+		Duration:   seconds(rj.DurationSeconds),
+		ExitReason: rj.ExitReason,
+	}
+	if rj.Error != nil {
+		rr.Error = rj.Error
+	}
+	return nil
+}
+
+// MarshalJSON encodes the status map along with StatusSchemaVersion.
+//
+// Example output:
+//
+//	{"version":1,"runnables":{"monitor":{"running":true,"restarts":0,"start_time":"2024-05-01T10:00:00Z","uptime_seconds":12.5}}}
+func (sm StatusMap) MarshalJSON() ([]byte, error) {
+	runnables := map[string]Status(sm)
+	if runnables == nil {
+		runnables = map[string]Status{}
+	}
+
+	return json.Marshal(statusMapJSON{
+		Version:   StatusSchemaVersion,
+		Runnables: runnables,
+	})
+}
+
+// UnmarshalJSON decodes a status map encoded by MarshalJSON. It fails if the
+// encoding is newer than StatusSchemaVersion.
+func (sm *StatusMap) UnmarshalJSON(data []byte) error {
+	var smj statusMapJSON
+	if err := json.Unmarshal(data, &smj); err != nil {
+		return err
+	}
+
+	if smj.Version > StatusSchemaVersion {
+		return fmt.Errorf("unsupported status schema version %d", smj.Version)
+	}
+
+	*sm = StatusMap(smj.Runnables)
+	if *sm == nil {
+		*sm = StatusMap{}
+	}
+	return nil
+}
+
+func newStatusError(err error) *StatusError {
+	if err == nil {
+		return nil
+	}
+
+	if statusErr, ok := err.(*StatusError); ok {
+		return &StatusError{
+			Message: statusErr.Message,
+			Type:    statusErr.Type,
+		}
+	}
+
+	return &StatusError{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
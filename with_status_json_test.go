@@ -0,0 +1,94 @@
+package runnable
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusJSON(t *testing.T) {
+
+	t.Run("marshal", func(t *testing.T) {
+		store := NewStatusStore()
+
+		r := New(func(ctx context.Context) error {
+			return assert.AnError
+		}, WithStatus("test", store))
+		require.Error(t, r.Run(context.Background()))
+
+		data, err := json.Marshal(store.Get())
+		require.NoError(t, err)
+
+		var raw map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &raw))
+		assert.Equal(t, float64(StatusSchemaVersion), raw["version"])
+
+		st := raw["runnables"].(map[string]interface{})["test"].(map[string]interface{})
+		assert.Equal(t, false, st["running"])
+		assert.Equal(t, map[string]interface{}{
+			"message": assert.AnError.Error(),
+			"type":    "*errors.errorString",
+		}, st["last_error"])
+	})
+
+	t.Run("uptime", func(t *testing.T) {
+		st := Status{
+			Running:   true,
+			StartTime: time.Now().Add(-10 * time.Second),
+		}
+
+		data, err := json.Marshal(st)
+		require.NoError(t, err)
+
+		var raw map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &raw))
+		assert.GreaterOrEqual(t, raw["uptime_seconds"], float64(10))
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		endTime := time.Date(2024, 5, 1, 10, 1, 0, 0, time.UTC)
+		sm := StatusMap{
+			"a": {
+				Running:   false,
+				Restarts:  2,
+				StartTime: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				EndTime:   &endTime,
+				LastError: assert.AnError,
+			},
+			"b": {
+				Running:   true,
+				StartTime: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			},
+		}
+
+		data, err := json.Marshal(sm)
+		require.NoError(t, err)
+
+		var decoded StatusMap
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Len(t, decoded, 2)
+		assert.Equal(t, 2, decoded["a"].Restarts)
+		assert.True(t, decoded["a"].StartTime.Equal(sm["a"].StartTime))
+		assert.True(t, decoded["a"].EndTime.Equal(endTime))
+		assert.Equal(t, &StatusError{Message: assert.AnError.Error(), Type: "*errors.errorString"}, decoded["a"].LastError)
+		assert.Equal(t, true, decoded["b"].Running)
+		assert.Nil(t, decoded["b"].LastError)
+
+		again, err := json.Marshal(decoded)
+		require.NoError(t, err)
+
+		var decodedAgain StatusMap
+		require.NoError(t, json.Unmarshal(again, &decodedAgain))
+		assert.Equal(t, decoded["a"].LastError, decodedAgain["a"].LastError)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		var sm StatusMap
+		err := json.Unmarshal([]byte(`{"version":999,"runnables":{}}`), &sm)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,149 @@
+package runnable
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultOutputLines is the default number of output lines a StatusStore keeps per ID.
+const DefaultOutputLines = 20
+
+// maxOutputLineSize is the maximum size of an output line; longer lines are split.
+const maxOutputLineSize = 4096
+
+// Output returns a writer for the output of the running runnable, e.g. its logs.
+// Its last lines are kept in the Output of the Status of the nearest runnable
+// wrapped with WithStatus whose run context is ctx or a parent of ctx, next to
+// its LastError (see WithOutputLines). Output returns io.Discard otherwise.
+//
+// The writer is shared by the whole run and is safe for concurrent use. A line is
+// recorded once it is terminated by a newline, or when the run ends. Output lines
+// are not reported to the watchers of a StatusStore (see StatusStore.Watch); they
+// are part of the Status of the next change of the runnable.
+//
+// Example:
+//
+//	func (w *Worker) run(ctx context.Context) error {
+//		logger := slog.New(slog.NewTextHandler(io.MultiWriter(os.Stderr, runnable.Output(ctx)), nil))
+//		logger.Info("processing", "queue", w.queue)
+//		...
+//	}
+func Output(ctx context.Context) io.Writer {
+	scope, ok := ctx.Value(statusScopeKey{}).(*statusScope)
+	if !ok {
+		return io.Discard
+	}
+	return scope.output
+}
+
+// outputWriter records the lines written to it as output events.
+type outputWriter struct {
+	backend StatusBackend
+	key     string
+
+	partial []byte
+	mu      sync.Mutex
+}
+
+func (o *outputWriter) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			o.partial = append(o.partial, p...)
+			for len(o.partial) >= maxOutputLineSize {
+				o.record(o.partial[:maxOutputLineSize])
+				o.partial = append(o.partial[:0], o.partial[maxOutputLineSize:]...)
+			}
+			break
+		}
+
+		line := p[:i]
+		if len(o.partial) > 0 {
+			line = append(o.partial, line...)
+			o.partial = o.partial[:0]
+		}
+		for len(line) > maxOutputLineSize {
+			o.record(line[:maxOutputLineSize])
+			line = line[maxOutputLineSize:]
+		}
+		o.record(bytes.TrimSuffix(line, []byte{'\r'}))
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// flush records the unterminated last line, if any. It is called when the run ends.
+func (o *outputWriter) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.partial) > 0 {
+		o.record(bytes.TrimSuffix(o.partial, []byte{'\r'}))
+		o.partial = o.partial[:0]
+	}
+}
+
+func (o *outputWriter) record(line []byte) {
+	o.backend.Record(StatusEvent{
+		ID:   o.key,
+		Type: StatusEventOutput,
+		Time: time.Now(),
+		Line: string(line),
+	})
+}
+
+type outputLines int
+
+// WithOutputLines sets the number of output lines the store keeps per ID, see
+// Output. The default is DefaultOutputLines; zero disables the output.
+func WithOutputLines(n int) StatusStoreOption {
+	return outputLines(n)
+}
+
+func (o outputLines) applyStore(s *StatusStore) {
+	s.outputLines = int(o)
+}
+
+type clearOutputOnRestart struct{}
+
+// WithClearOutputOnRestart makes the store clear the output of a runnable when it
+// starts a new run, retries included. By default, the output is preserved across
+// restarts, so that the lines that led to a failure are kept.
+func WithClearOutputOnRestart() StatusStoreOption {
+	return clearOutputOnRestart{}
+}
+
+func (clearOutputOnRestart) applyStore(s *StatusStore) {
+	s.clearOutput = true
+}
+
+// runOutput records an output line of id. Unlike the other events, it does not
+// go through update: the watchers are not notified of each line, and the snapshot
+// it schedules, if the store is persisted, is coalesced with the others.
+func (s *StatusStore) runOutput(id string, line string) {
+	if s.outputLines <= 0 {
+		return
+	}
+
+	shard := s.shard(id)
+	shard.mu.Lock()
+	rec, ok := shard.records[id]
+	if !ok {
+		rec = &statusRecord{}
+		shard.records[id] = rec
+	}
+	if rec.output == nil {
+		rec.output = newRing[string](s.outputLines)
+	}
+	rec.output.push(line)
+	shard.mu.Unlock()
+
+	s.markDirty()
+}
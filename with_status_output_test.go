@@ -0,0 +1,232 @@
+package runnable
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutput(t *testing.T) {
+
+	t.Run("last lines", func(t *testing.T) {
+		store := NewStatusStore(WithOutputLines(3))
+
+		r := New(func(ctx context.Context) error {
+			for i := 1; i <= 5; i++ {
+				fmt.Fprintf(Output(ctx), "line %d\n", i)
+			}
+			return fmt.Errorf("failed")
+		}, WithStatus("test", store))
+		require.Error(t, r.Run(context.Background()))
+
+		st := store.Get()["test"]
+		assert.Equal(t, []string{"line 3", "line 4", "line 5"}, st.Output)
+		assert.EqualError(t, st.LastError, "failed")
+
+		st.Output[0] = "modified"
+		assert.Equal(t, "line 3", store.Get()["test"].Output[0])
+	})
+
+	t.Run("partial lines", func(t *testing.T) {
+		store := NewStatusStore()
+
+		r := New(func(ctx context.Context) error {
+			w := Output(ctx)
+			io.WriteString(w, "hel")
+			io.WriteString(w, "lo\r\nwor")
+			io.WriteString(w, "ld\n\nunterminated")
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Equal(t, []string{"hello", "world", "", "unterminated"}, store.Get()["test"].Output)
+	})
+
+	t.Run("not watched", func(t *testing.T) {
+		store := NewStatusStore()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		changes := store.Watch(ctx)
+
+		r := New(func(ctx context.Context) error {
+			for i := 1; i <= 5; i++ {
+				fmt.Fprintf(Output(ctx), "line %d\n", i)
+			}
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		// started, ended and stopped
+		var last StatusChange
+		for i := 0; i < 3; i++ {
+			last = <-changes
+		}
+		assert.False(t, last.New.Running)
+		assert.Equal(t, []string{"line 1", "line 2", "line 3", "line 4", "line 5"}, last.New.Output)
+		select {
+		case change := <-changes:
+			assert.Fail(t, "unexpected change", "%+v", change)
+		case <-time.After(20 * time.Millisecond):
+		}
+	})
+
+	t.Run("long lines", func(t *testing.T) {
+		store := NewStatusStore()
+
+		r := New(func(ctx context.Context) error {
+			io.WriteString(Output(ctx), strings.Repeat("a", maxOutputLineSize+10)+"\n")
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		output := store.Get()["test"].Output
+		require.Len(t, output, 2)
+		assert.Len(t, output[0], maxOutputLineSize)
+		assert.Equal(t, strings.Repeat("a", 10), output[1])
+	})
+
+	t.Run("slog", func(t *testing.T) {
+		store := NewStatusStore()
+
+		r := New(func(ctx context.Context) error {
+			logger := slog.New(slog.NewTextHandler(Output(ctx), nil))
+			logger.Info("processing", "queue", "jobs")
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		output := store.Get()["test"].Output
+		require.Len(t, output, 1)
+		assert.Contains(t, output[0], "msg=processing queue=jobs")
+	})
+
+	t.Run("preserved across restarts", func(t *testing.T) {
+		store := NewStatusStore()
+
+		attempt := 0
+		r := New(func(ctx context.Context) error {
+			attempt++
+			fmt.Fprintf(Output(ctx), "attempt %d\n", attempt)
+			return fmt.Errorf("failed")
+		}, WithRetry(2, ResetNever), WithStatus("test", store))
+		require.Error(t, r.Run(context.Background()))
+
+		assert.Equal(t, []string{"attempt 1", "attempt 2"}, store.Get()["test"].Output)
+	})
+
+	t.Run("cleared on restart", func(t *testing.T) {
+		store := NewStatusStore(WithClearOutputOnRestart())
+
+		attempt := 0
+		r := New(func(ctx context.Context) error {
+			attempt++
+			fmt.Fprintf(Output(ctx), "attempt %d\n", attempt)
+			return fmt.Errorf("failed")
+		}, WithRetry(2, ResetNever), WithStatus("test", store))
+		require.Error(t, r.Run(context.Background()))
+		assert.Equal(t, []string{"attempt 2"}, store.Get()["test"].Output)
+
+		r = New(func(ctx context.Context) error {
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+		assert.Nil(t, store.Get()["test"].Output)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		store := NewStatusStore(WithOutputLines(0))
+
+		r := New(func(ctx context.Context) error {
+			io.WriteString(Output(ctx), "line\n")
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Nil(t, store.Get()["test"].Output)
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		store := NewStatusStore(WithOutputLines(100))
+
+		r := New(func(ctx context.Context) error {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 10; j++ {
+						io.WriteString(Output(ctx), "line\n")
+					}
+				}()
+			}
+			wg.Wait()
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		output := store.Get()["test"].Output
+		assert.Len(t, output, 100)
+		for _, line := range output {
+			assert.Equal(t, "line", line)
+		}
+	})
+
+	t.Run("group", func(t *testing.T) {
+		store := NewStatusStore()
+
+		group := NewGroupWithOptions([]Runnable{
+			New(func(ctx context.Context) error {
+				io.WriteString(Output(ctx), "anonymous\n")
+				return nil
+			}),
+			New(func(ctx context.Context) error {
+				io.WriteString(Output(ctx), "worker\n")
+				return nil
+			}, WithStatus("worker", store)),
+		}, WithStatus("api", store))
+		require.NoError(t, group.Run(context.Background()))
+
+		sm := store.Get()
+		assert.Equal(t, []string{"anonymous"}, sm["api"].Output)
+		assert.Equal(t, []string{"worker"}, sm["api/worker"].Output)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		store := NewStatusStore()
+
+		r := New(func(ctx context.Context) error {
+			io.WriteString(Output(ctx), "first\nsecond\n")
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		data, err := json.Marshal(store.Get())
+		require.NoError(t, err)
+
+		var sm StatusMap
+		require.NoError(t, json.Unmarshal(data, &sm))
+		assert.Equal(t, []string{"first", "second"}, sm["test"].Output)
+
+		loaded := NewStatusStore()
+		loaded.load(sm)
+		assert.Equal(t, []string{"first", "second"}, loaded.Get()["test"].Output)
+	})
+
+	t.Run("without status", func(t *testing.T) {
+		r := New(func(ctx context.Context) error {
+			assert.Equal(t, io.Discard, Output(ctx))
+			return nil
+		})
+		require.NoError(t, r.Run(context.Background()))
+	})
+}
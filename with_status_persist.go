@@ -0,0 +1,208 @@
+package runnable
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// DefaultPersistInterval is the default interval between two snapshots written by
+// a store opened with OpenStatusStore.
+const DefaultPersistInterval = time.Second
+
+type persistInterval time.Duration
+
+// WithPersistInterval sets the interval between two snapshots written by a store
+// opened with OpenStatusStore. The default is DefaultPersistInterval.
+func WithPersistInterval(interval time.Duration) StatusStoreOption {
+	return persistInterval(interval)
+}
+
+func (p persistInterval) applyStore(s *StatusStore) {
+	s.persistInterval = time.Duration(p)
+}
+
+// OpenStatusStore creates a new StatusStore persisted to the file at path. The
+// last snapshot is loaded from the file, if it exists, and a new snapshot is
+// atomically written to it after changes, so that restart counts, last errors
+// and history survive process restarts.
+//
+// Snapshots are written in the background: the changes made within the interval
+// set by WithPersistInterval are written in a single snapshot, and recording a
+// change never waits for the disk. Close the store to write the last changes.
+//
+// Loaded runnables are reported as not running until they start again. Restarts
+// counts the restarts of the current process only, while TotalRestarts carries
+// on from the loaded snapshot.
+//
+// Example:
+//
+//	store, err := runnable.OpenStatusStore("/var/lib/app/status.json")
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+func OpenStatusStore(path string, options ...StatusStoreOption) (*StatusStore, error) {
+	s := NewStatusStore(options...)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if err == nil {
+		var sm StatusMap
+		if err := json.Unmarshal(data, &sm); err != nil {
+			return nil, err
+		}
+		s.load(sm)
+	}
+
+	s.path = path
+	s.persistSignal = make(chan struct{}, 1)
+	s.persistClose = make(chan struct{})
+	s.persistDone = make(chan struct{})
+	go s.persistLoop()
+
+	return s, nil
+}
+
+// Close writes the last changes of a store opened with OpenStatusStore to its
+// file, stops writing snapshots, and returns the error of the last snapshot. It
+// does nothing on other stores.
+func (s *StatusStore) Close() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.closeOnce.Do(func() {
+		close(s.persistClose)
+	})
+	<-s.persistDone
+
+	return s.PersistError()
+}
+
+// PersistError returns the error of the last snapshot written by a store opened
+// with OpenStatusStore, or nil if it succeeded.
+func (s *StatusStore) PersistError() error {
+	s.persistErrMu.Lock()
+	defer s.persistErrMu.Unlock()
+	return s.persistErr
+}
+
+func (s *StatusStore) load(sm StatusMap) {
+	for id, st := range sm {
+		rec := &statusRecord{
+			totalRestarts: st.TotalRestarts,
+			startTime:     st.StartTime,
+			lastError:     st.LastError,
+			exitReason:    st.ExitReason,
+			parent:        st.Parent,
+			optional:      st.Optional,
+			counters: statusCounters{
+				runs:      st.Runs,
+				successes: st.SuccessCount,
+				failures:  st.FailureCount,
+				panics:    st.PanicCount,
+				runTime:   st.TotalRunTime - st.Uptime,
+			},
+		}
+
+		if st.EndTime != nil {
+			rec.endTime = *st.EndTime
+		}
+		if st.LastErrorTime != nil {
+			rec.lastErrorTime = *st.LastErrorTime
+		}
+		if st.LastSuccessTime != nil {
+			rec.counters.lastSuccessTime = *st.LastSuccessTime
+		}
+		if st.LastFailureTime != nil {
+			rec.counters.lastFailureTime = *st.LastFailureTime
+		}
+		if len(st.Details) > 0 {
+			rec.details = st.Details
+		}
+
+		if s.outputLines > 0 && len(st.Output) > 0 {
+			rec.output = newRing[string](s.outputLines)
+			for _, line := range st.Output {
+				rec.output.push(line)
+			}
+		}
+
+		if s.historySize > 0 && len(st.History) > 0 {
+			rec.history = newRing[RunRecord](s.historySize)
+			for _, record := range st.History {
+				rec.history.push(record)
+			}
+		}
+
+		shard := s.shard(id)
+		shard.mu.Lock()
+		shard.records[id] = rec
+		shard.mu.Unlock()
+	}
+}
+
+// markDirty schedules a snapshot of a store opened with OpenStatusStore.
+func (s *StatusStore) markDirty() {
+	if s.path == "" {
+		return
+	}
+
+	s.dirty.Store(true)
+	select {
+	case s.persistSignal <- struct{}{}:
+	default:
+	}
+}
+
+// persistLoop writes a snapshot at most once per interval while the store has
+// changes, and a last one when the store is closed.
+func (s *StatusStore) persistLoop() {
+	defer close(s.persistDone)
+
+	for {
+		select {
+		case <-s.persistSignal:
+		case <-s.persistClose:
+			s.flush()
+			return
+		}
+
+		timer := time.NewTimer(s.persistInterval)
+		select {
+		case <-timer.C:
+		case <-s.persistClose:
+			timer.Stop()
+			s.flush()
+			return
+		}
+
+		s.flush()
+	}
+}
+
+// flush writes a snapshot if the store changed since the last one.
+func (s *StatusStore) flush() {
+	if s.dirty.Swap(false) {
+		s.persist()
+	}
+}
+
+// persist writes a snapshot of the store to its file. It is only called by
+// persistLoop, so snapshots never race.
+func (s *StatusStore) persist() {
+	data, err := json.Marshal(s.Get())
+	if err == nil {
+		err = writeFileAtomic(s.path, data)
+	}
+
+	s.persistErrMu.Lock()
+	s.persistErr = err
+	s.persistErrMu.Unlock()
+}
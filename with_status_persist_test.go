@@ -0,0 +1,142 @@
+package runnable
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusPersistence(t *testing.T) {
+
+	t.Run("survives restarts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "status.json")
+
+		run := func(store *StatusStore) {
+			counter := 0
+			r := New(func(ctx context.Context) error {
+				defer func() { counter++ }()
+				if counter < 2 {
+					return assert.AnError
+				}
+				return nil
+			}, WithStatus("test", store), WithRetry(3, ResetNever))
+			require.NoError(t, r.Run(context.Background()))
+		}
+
+		// first process
+		store, err := OpenStatusStore(path)
+		require.NoError(t, err)
+		run(store)
+		require.NoError(t, store.Close())
+
+		s := store.Get()["test"]
+		assert.Equal(t, 2, s.Restarts)
+		assert.Equal(t, 2, s.TotalRestarts)
+
+		// second process
+		store, err = OpenStatusStore(path)
+		require.NoError(t, err)
+
+		s = store.Get()["test"]
+		assert.Equal(t, false, s.Running)
+		assert.Equal(t, 0, s.Restarts)
+		assert.Equal(t, 2, s.TotalRestarts)
+		assert.Equal(t, assert.AnError.Error(), s.LastError.Error())
+		assert.NotNil(t, s.LastErrorTime)
+		assert.Len(t, store.History("test"), 3)
+
+		run(store)
+
+		s = store.Get()["test"]
+		assert.Equal(t, 2, s.Restarts)
+		assert.Equal(t, 5, s.TotalRestarts)
+		assert.Equal(t, 6, s.Runs)
+		assert.Equal(t, 2, s.SuccessCount)
+		assert.Equal(t, 4, s.FailureCount)
+		assert.Len(t, store.History("test"), 6)
+		require.NoError(t, store.Close())
+	})
+
+	t.Run("coalesced snapshots", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "status.json")
+
+		store, err := OpenStatusStore(path, WithPersistInterval(time.Hour))
+		require.NoError(t, err)
+
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, WithStatus("test", store))
+		for i := 0; i < 10; i++ {
+			require.NoError(t, r.Run(context.Background()))
+		}
+
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+
+		require.NoError(t, store.Close())
+		require.NoError(t, store.Close())
+
+		store, err = OpenStatusStore(path)
+		require.NoError(t, err)
+		defer store.Close()
+		assert.Equal(t, 10, store.Get()["test"].Runs)
+	})
+
+	t.Run("persist interval", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "status.json")
+
+		store, err := OpenStatusStore(path, WithPersistInterval(10*time.Millisecond))
+		require.NoError(t, err)
+		defer store.Close()
+
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "status.json")
+
+		store, err := OpenStatusStore(path)
+		require.NoError(t, err)
+		assert.Empty(t, store.Get())
+		require.NoError(t, store.Close())
+
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("corrupted file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "status.json")
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+		_, err := OpenStatusStore(path)
+		require.Error(t, err)
+	})
+
+	t.Run("persist error", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "status.json")
+
+		store, err := OpenStatusStore(path)
+		require.NoError(t, err)
+
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		require.Error(t, store.Close())
+		require.Error(t, store.PersistError())
+	})
+}
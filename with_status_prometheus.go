@@ -0,0 +1,181 @@
+package runnable
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes the status of the store to w in the Prometheus text
+// exposition format, with the following metrics:
+//
+//   - runnable_up{id}                      1 if the runnable is running, 0 otherwise
+//   - runnable_restarts_total{id}          number of restarts since the process started
+//   - runnable_start_time_seconds{id}      unix time of the last start
+//   - runnable_last_error_timestamp{id}    unix time of the last error, only for runnables that failed
+//
+// Like any counter, runnable_restarts_total goes back to zero when the process
+// restarts. It also does when the ID is removed from the store, see Remove and
+// WithEvictAfter, and recreated later. Prometheus functions such as rate and
+// increase handle these resets.
+func (s *StatusStore) WritePrometheus(w io.Writer) error {
+	sm := s.Get()
+
+	ids := make([]string, 0, len(sm))
+	for id := range sm {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	bw := bufio.NewWriter(w)
+
+	writePrometheusHeader(bw, "runnable_up", "gauge", "Whether the runnable is running (1) or not (0).")
+	for _, id := range ids {
+		up := 0
+		if sm[id].Running {
+			up = 1
+		}
+		writePrometheusSample(bw, "runnable_up", id, float64(up))
+	}
+
+	writePrometheusHeader(bw, "runnable_restarts_total", "counter", "Number of times the runnable was restarted.")
+	for _, id := range ids {
+		writePrometheusSample(bw, "runnable_restarts_total", id, float64(sm[id].Restarts))
+	}
+
+	writePrometheusHeader(bw, "runnable_start_time_seconds", "gauge", "Unix time the runnable last started.")
+	for _, id := range ids {
+		if !sm[id].StartTime.IsZero() {
+			writePrometheusSample(bw, "runnable_start_time_seconds", id, unixSeconds(sm[id].StartTime))
+		}
+	}
+
+	writePrometheusHeader(bw, "runnable_last_error_timestamp", "gauge", "Unix time the runnable last returned an error.")
+	for _, id := range ids {
+		if sm[id].LastErrorTime != nil {
+			writePrometheusSample(bw, "runnable_last_error_timestamp", id, unixSeconds(*sm[id].LastErrorTime))
+		}
+	}
+
+	return bw.Flush()
+}
+
+// PrometheusHandler returns an http.Handler that serves the status of the store in
+// the Prometheus text exposition format, see WritePrometheus.
+//
+// Example:
+//
+//	http.Handle("/metrics/runnables", store.PrometheusHandler())
+func (s *StatusStore) PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := &bytes.Buffer{}
+		if err := s.WritePrometheus(buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", PrometheusContentType)
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// WriteTextfile atomically writes the status of the store, in the Prometheus text
+// exposition format, to the file at path. It is meant to be used with the
+// node_exporter textfile collector, whose files must have the .prom extension.
+func (s *StatusStore) WriteTextfile(path string) error {
+	buf := &bytes.Buffer{}
+	if err := s.WritePrometheus(buf); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// NewTextfileExporter creates a new Runnable that writes the status of the store
+// to the file at path every interval, see StatusStore.WriteTextfile. The file is
+// also written when the runnable starts and when it stops.
+//
+// A failed write is logged and retried at the next tick, so that a transient
+// error, e.g. a full disk, does not stop the export; only the error of the last
+// write, when the runnable stops, is returned.
+//
+// Example:
+//
+//	exporter := runnable.NewTextfileExporter(store, "/var/lib/node_exporter/textfile/app.prom", 15*time.Second)
+//	go exporter.Run(ctx)
+func NewTextfileExporter(store *StatusStore, path string, interval time.Duration) Runnable {
+	return New(func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := store.WriteTextfile(path); err != nil {
+				log.Printf("runnable: textfile exporter: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return store.WriteTextfile(path)
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+func writePrometheusHeader(w *bufio.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writePrometheusSample(w *bufio.Writer, name, id string, value float64) {
+	fmt.Fprintf(w, "%s{id=\"%s\"} %s\n", name, escapePrometheusLabel(id), strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapePrometheusLabel(value string) string {
+	return prometheusLabelEscaper.Replace(value)
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+// writeFileAtomic writes data to a temporary file next to path, then renames it
+// to path, so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
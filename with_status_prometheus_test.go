@@ -0,0 +1,95 @@
+package runnable
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusPrometheus(t *testing.T) {
+	store := NewStatusStore()
+
+	counter := 0
+	r := New(func(ctx context.Context) error {
+		defer func() { counter++ }()
+		if counter < 1 {
+			return assert.AnError
+		}
+		return nil
+	}, WithStatus(`a"b`, store), WithRetry(3, ResetNever))
+	require.NoError(t, r.Run(context.Background()))
+
+	t.Run("write", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, store.WritePrometheus(buf))
+
+		out := buf.String()
+		assert.Contains(t, out, "# TYPE runnable_up gauge\n")
+		assert.Contains(t, out, "runnable_up{id=\"a\\\"b\"} 0\n")
+		assert.Contains(t, out, "# TYPE runnable_restarts_total counter\n")
+		assert.Contains(t, out, "runnable_restarts_total{id=\"a\\\"b\"} 1\n")
+		assert.Contains(t, out, "runnable_start_time_seconds{id=\"a\\\"b\"} ")
+		assert.Contains(t, out, "runnable_last_error_timestamp{id=\"a\\\"b\"} ")
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		store.PrometheusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, PrometheusContentType, rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), "runnable_up")
+	})
+
+	t.Run("textfile exporter", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "runnables.prom")
+
+		exporter := NewTextfileExporter(store, path, 10*time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		require.NoError(t, exporter.Run(ctx))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(data), "# HELP runnable_up"))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("textfile exporter, write error", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "textfile")
+		path := filepath.Join(dir, "runnables.prom")
+
+		exporter := NewTextfileExporter(store, path, 10*time.Millisecond)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- exporter.Run(ctx)
+		}()
+
+		time.Sleep(30 * time.Millisecond)
+		assert.True(t, exporter.IsRunning())
+
+		require.NoError(t, os.Mkdir(dir, 0o755))
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+
+		cancel()
+		require.NoError(t, <-errCh)
+	})
+}
@@ -0,0 +1,152 @@
+package runnable
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrDuplicateStatusID is returned by Run when another running runnable already
+// records its status under the same ID in the same store.
+var ErrDuplicateStatusID = fmt.Errorf("duplicate status id")
+
+// StatusClaimer is implemented by backends that detect runnables sharing an ID.
+// WithStatus claims the ID of the runnable before each run, and releases it after.
+type StatusClaimer interface {
+	// Claim claims id for owner. It returns an error wrapping ErrDuplicateStatusID
+	// if id is already claimed by another owner.
+	Claim(id string, owner interface{}) error
+	// Release releases id, if it is claimed by owner.
+	Release(id string, owner interface{})
+}
+
+var (
+	_ StatusClaimer = (*StatusStore)(nil)
+	_ StatusClaimer = (*FanOutBackend)(nil)
+)
+
+type evictAfter time.Duration
+
+// WithEvictAfter makes the store evict the entries of runnables that have not been
+// running for longer than ttl, e.g. per-tenant workers that are gone. Eviction
+// happens on Get and EvictStale.
+func WithEvictAfter(ttl time.Duration) StatusStoreOption {
+	return evictAfter(ttl)
+}
+
+func (e evictAfter) applyStore(s *StatusStore) {
+	s.evictAfter = time.Duration(e)
+}
+
+func (s *StatusStore) Claim(id string, owner interface{}) error {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if current, ok := shard.owners[id]; ok && current != owner {
+		return fmt.Errorf("%w: %q", ErrDuplicateStatusID, id)
+	}
+	shard.owners[id] = owner
+	return nil
+}
+
+func (s *StatusStore) Release(id string, owner interface{}) {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if shard.owners[id] == owner {
+		delete(shard.owners, id)
+	}
+}
+
+// Remove removes the entry of the runnable with the given ID from the store and
+// returns true if it existed. If the runnable is still running, it is added back
+// on its next lifecycle event.
+func (s *StatusStore) Remove(id string) bool {
+	shard := s.shard(id)
+	shard.mu.Lock()
+	ok := s.removeLocked(shard, id)
+	shard.mu.Unlock()
+
+	if ok {
+		s.markDirty()
+	}
+	return ok
+}
+
+// EvictStale removes the entries of the runnables that have not been running for
+// longer than the duration set by WithEvictAfter, and returns their IDs. It does
+// nothing if the store has no such duration.
+func (s *StatusStore) EvictStale() []string {
+	if s.evictAfter <= 0 {
+		return nil
+	}
+
+	var evicted []string
+	now := time.Now()
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		for id, rec := range shard.records {
+			if rec.running {
+				continue
+			}
+			if _, ok := shard.owners[id]; ok {
+				continue
+			}
+			if !rec.endTime.IsZero() && now.Sub(rec.endTime) > s.evictAfter {
+				s.removeLocked(shard, id)
+				evicted = append(evicted, id)
+			}
+		}
+		shard.mu.Unlock()
+	}
+
+	if len(evicted) > 0 {
+		s.markDirty()
+	}
+	return evicted
+}
+
+// removeLocked removes the record of id from shard, which must be locked.
+func (s *StatusStore) removeLocked(shard *statusShard, id string) bool {
+	rec, ok := shard.records[id]
+	if !ok {
+		return false
+	}
+	delete(shard.records, id)
+
+	if s.watching.Load() > 0 {
+		s.notify(StatusChange{ID: id, Old: rec.status(), Removed: true})
+	}
+	return true
+}
+
+// Claim claims id in every backend that is a StatusClaimer. If one of them fails,
+// the claims already made are released.
+func (f *FanOutBackend) Claim(id string, owner interface{}) error {
+	for i, backend := range f.backends {
+		claimer, ok := backend.(StatusClaimer)
+		if !ok {
+			continue
+		}
+		if err := claimer.Claim(id, owner); err != nil {
+			f.release(f.backends[:i], id, owner)
+			return err
+		}
+	}
+	return nil
+}
+
+// Release releases id in every backend that is a StatusClaimer.
+func (f *FanOutBackend) Release(id string, owner interface{}) {
+	f.release(f.backends, id, owner)
+}
+
+func (f *FanOutBackend) release(backends []StatusBackend, id string, owner interface{}) {
+	for _, backend := range backends {
+		if claimer, ok := backend.(StatusClaimer); ok {
+			claimer.Release(id, owner)
+		}
+	}
+}
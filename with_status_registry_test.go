@@ -0,0 +1,134 @@
+package runnable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusRegistry(t *testing.T) {
+
+	t.Run("remove", func(t *testing.T) {
+		store := NewStatusStore()
+
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		changes := store.Watch(ctx)
+
+		assert.True(t, store.Remove("test"))
+		assert.False(t, store.Remove("test"))
+		assert.Empty(t, store.Get())
+		assert.Nil(t, store.History("test"))
+
+		change := <-changes
+		assert.Equal(t, "test", change.ID)
+		assert.True(t, change.Removed)
+		assert.Equal(t, 0, change.Old.Restarts)
+		assert.Equal(t, 1, change.Old.Runs)
+	})
+
+	t.Run("evict stale entries", func(t *testing.T) {
+		store := NewStatusStore(WithEvictAfter(50 * time.Millisecond))
+
+		stopped := New(func(ctx context.Context) error {
+			return nil
+		}, WithStatus("stopped", store))
+		require.NoError(t, stopped.Run(context.Background()))
+
+		started := make(chan struct{})
+		running := New(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			return nil
+		}, WithStatus("running", store))
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- running.Run(context.Background())
+		}()
+		<-started
+		defer func() {
+			require.NoError(t, running.Stop(context.Background()))
+			require.NoError(t, <-errCh)
+		}()
+
+		assert.Len(t, store.Get(), 2)
+		assert.Empty(t, store.EvictStale())
+
+		time.Sleep(100 * time.Millisecond)
+
+		sm := store.Get()
+		assert.Len(t, sm, 1)
+		assert.Contains(t, sm, "running")
+	})
+
+	t.Run("evict disabled", func(t *testing.T) {
+		store := NewStatusStore()
+
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, WithStatus("test", store))
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Nil(t, store.EvictStale())
+		assert.Len(t, store.Get(), 1)
+	})
+
+	t.Run("duplicate id", func(t *testing.T) {
+		store := NewStatusStore()
+
+		started := make(chan struct{})
+		r1 := New(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			return nil
+		}, WithStatus("test", store))
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- r1.Run(context.Background())
+		}()
+		<-started
+
+		ran := false
+		r2 := New(func(ctx context.Context) error {
+			ran = true
+			return nil
+		}, WithStatus("test", NewFanOutBackend(NewStatusStore(), store)))
+
+		err := r2.Run(context.Background())
+		require.ErrorIs(t, err, ErrDuplicateStatusID)
+		assert.False(t, ran)
+		assert.False(t, r2.IsRunning())
+		assert.Equal(t, 0, store.Get()["test"].Restarts)
+
+		require.NoError(t, r1.Stop(context.Background()))
+		require.NoError(t, <-errCh)
+
+		err = r2.Run(context.Background())
+		require.NoError(t, err)
+		assert.True(t, ran)
+	})
+
+	t.Run("same runnable, same id", func(t *testing.T) {
+		store := NewStatusStore()
+		option := WithStatus("test", store)
+
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, option)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, r.Run(context.Background()))
+		}
+		assert.Equal(t, 2, store.Get()["test"].Restarts)
+	})
+}
@@ -2,6 +2,10 @@ package runnable
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -72,4 +76,312 @@ func TestWithStatus(t *testing.T) {
 		assert.Equal(t, false, s["test"].Running)
 		assert.Equal(t, 1, s["test"].Restarts)
 	})
+
+	t.Run("with status, counters", func(t *testing.T) {
+		store := NewStatusStore()
+
+		counter := 0
+		r := New(func(ctx context.Context) error {
+			defer func() { counter++ }()
+			time.Sleep(10 * time.Millisecond)
+			switch counter {
+			case 0:
+				return assert.AnError
+			case 1:
+				panic("something went wrong")
+			case 2:
+				return context.Canceled
+			}
+			return nil
+		}, WithRecoverer(nil, nil), WithStatus("test", store), WithRetry(5, ResetNever))
+
+		require.ErrorIs(t, r.Run(context.Background()), context.Canceled)
+		require.NoError(t, r.Run(context.Background()))
+
+		s := store.Get()["test"]
+		assert.Equal(t, 4, s.Runs)
+		assert.Equal(t, 1, s.SuccessCount)
+		assert.Equal(t, 2, s.FailureCount)
+		assert.Equal(t, 1, s.PanicCount)
+		require.NotNil(t, s.LastSuccessTime)
+		require.NotNil(t, s.LastFailureTime)
+		assert.True(t, s.LastSuccessTime.After(*s.LastFailureTime))
+		assert.Equal(t, time.Duration(0), s.Uptime)
+		assert.GreaterOrEqual(t, s.TotalRunTime, 40*time.Millisecond)
+		assert.Equal(t, s.TotalRunTime/4, s.MeanRunDuration)
+	})
+
+	t.Run("with status, uptime", func(t *testing.T) {
+		store := NewStatusStore()
+
+		started := make(chan struct{})
+		r := New(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			return nil
+		}, WithStatus("test", store))
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- r.Run(context.Background())
+		}()
+
+		<-started
+		time.Sleep(20 * time.Millisecond)
+
+		s := store.Get()["test"]
+		assert.GreaterOrEqual(t, s.Uptime, 20*time.Millisecond)
+		assert.Equal(t, s.Uptime, s.TotalRunTime)
+		assert.Equal(t, 0, s.Runs)
+
+		require.NoError(t, r.Stop(context.Background()))
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("with status, with retry", func(t *testing.T) {
+		options := map[string]func(store StatusBackend) Option{
+			"status":  func(store StatusBackend) Option { return WithStatus("test", store) },
+			"retry":   func(store StatusBackend) Option { return WithRetry(3, ResetNever) },
+			"limiter": func(store StatusBackend) Option { return WithRestartLimiter(NewRestartLimiter(time.Millisecond, 1)) },
+		}
+		orderings := [][]string{
+			{"status", "retry"},
+			{"retry", "status"},
+			{"status", "retry", "limiter"},
+			{"status", "limiter", "retry"},
+			{"retry", "status", "limiter"},
+			{"retry", "limiter", "status"},
+			{"limiter", "status", "retry"},
+			{"limiter", "retry", "status"},
+		}
+
+		for _, ordering := range orderings {
+			ordering := ordering
+			t.Run(strings.Join(ordering, ", "), func(t *testing.T) {
+				store := NewStatusStore()
+				recorder := &recordingBackend{}
+				backend := NewFanOutBackend(store, recorder)
+
+				var opts []Option
+				for _, name := range ordering {
+					opts = append(opts, options[name](backend))
+				}
+
+				attempts := 0
+				r := New(func(ctx context.Context) error {
+					attempts++
+					time.Sleep(time.Millisecond)
+					if attempts < 3 {
+						return assert.AnError
+					}
+					return nil
+				}, opts...)
+				require.NoError(t, r.Run(context.Background()))
+				require.Equal(t, 3, attempts)
+
+				s := store.Get()["test"]
+				assert.False(t, s.Running)
+				assert.Equal(t, 2, s.Restarts)
+				assert.Equal(t, 3, s.Runs)
+				assert.Equal(t, 1, s.SuccessCount)
+				assert.Equal(t, 2, s.FailureCount)
+				assert.Equal(t, assert.AnError, s.LastError)
+				require.NotNil(t, s.EndTime)
+				assert.True(t, s.EndTime.After(s.StartTime))
+
+				require.Len(t, s.History, 3)
+				assert.Equal(t, ExitReasonError, s.History[0].ExitReason)
+				assert.Equal(t, ExitReasonError, s.History[1].ExitReason)
+				assert.Equal(t, ExitReasonSuccess, s.History[2].ExitReason)
+				assert.Equal(t, s.StartTime, s.History[2].StartTime)
+				for i := 1; i < len(s.History); i++ {
+					assert.False(t, s.History[i].StartTime.Before(s.History[i-1].EndTime))
+				}
+
+				var types []StatusEventType
+				for _, ev := range recorder.events {
+					types = append(types, ev.Type)
+				}
+				attempt := []StatusEventType{StatusEventStarted, StatusEventEnded, StatusEventStopped}
+				assert.Equal(t, append(append(attempt, attempt...), attempt...), types)
+			})
+		}
+	})
+
+	t.Run("with status, with retry, all attempts failed", func(t *testing.T) {
+		for _, statusFirst := range []bool{true, false} {
+			store := NewStatusStore()
+
+			opts := []Option{WithStatus("test", store), WithRetry(3, ResetNever)}
+			if !statusFirst {
+				opts[0], opts[1] = opts[1], opts[0]
+			}
+
+			r := New(func(ctx context.Context) error {
+				return assert.AnError
+			}, opts...)
+			require.ErrorIs(t, r.Run(context.Background()), assert.AnError)
+
+			s := store.Get()["test"]
+			assert.False(t, s.Running)
+			assert.Equal(t, 2, s.Restarts)
+			assert.Equal(t, 3, s.Runs)
+			assert.Equal(t, 3, s.FailureCount)
+			assert.Len(t, s.History, 3)
+		}
+	})
+
+	t.Run("with status, with retry, canceled while waiting", func(t *testing.T) {
+		for _, statusFirst := range []bool{true, false} {
+			store := NewStatusStore()
+
+			limiter := NewRestartLimiter(time.Hour, 1)
+			opts := []Option{WithStatus("test", store), WithRetry(3, ResetNever), WithRestartLimiter(limiter)}
+			if !statusFirst {
+				opts[0], opts[1] = opts[1], opts[0]
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			attempts := 0
+			r := New(func(ctx context.Context) error {
+				attempts++
+				if attempts == 2 {
+					go func() {
+						time.Sleep(20 * time.Millisecond)
+						cancel()
+					}()
+				}
+				return assert.AnError
+			}, opts...)
+			require.ErrorIs(t, r.Run(ctx), context.Canceled)
+			assert.Equal(t, 2, attempts)
+
+			s := store.Get()["test"]
+			assert.False(t, s.Running)
+			assert.Equal(t, 1, s.Restarts)
+			assert.Equal(t, 2, s.Runs)
+			assert.Equal(t, 2, s.FailureCount)
+			assert.Equal(t, assert.AnError, s.LastError)
+		}
+	})
+
+	t.Run("get one", func(t *testing.T) {
+		store := NewStatusStore()
+
+		r := New(func(ctx context.Context) error {
+			return assert.AnError
+		}, WithStatus("test", store))
+		require.Error(t, r.Run(context.Background()))
+
+		s, ok := store.GetOne("test")
+		require.True(t, ok)
+		assert.Equal(t, store.Get()["test"].LastError, s.LastError)
+		assert.Equal(t, 1, s.Runs)
+
+		_, ok = store.GetOne("unknown")
+		assert.False(t, ok)
+	})
+
+	t.Run("concurrent ids", func(t *testing.T) {
+		store := NewStatusStore()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 200; i++ {
+			id := fmt.Sprintf("worker-%d", i)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r := New(func(ctx context.Context) error {
+					return nil
+				}, WithStatus(id, store))
+				for j := 0; j < 5; j++ {
+					assert.NoError(t, r.Run(context.Background()))
+				}
+			}()
+		}
+		wg.Wait()
+
+		sm := store.Get()
+		require.Len(t, sm, 200)
+		for id, s := range sm {
+			assert.Equal(t, 5, s.Runs, id)
+			assert.Equal(t, 4, s.Restarts, id)
+		}
+	})
+}
+
+func BenchmarkStatusStore(b *testing.B) {
+	const ids = 1000
+
+	newStore := func() (*StatusStore, []string) {
+		store := NewStatusStore()
+		keys := make([]string, ids)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("worker-%d", i)
+			store.Record(StatusEvent{ID: keys[i], Type: StatusEventStarted, Time: time.Now()})
+		}
+		return store, keys
+	}
+
+	b.Run("update, distinct ids", func(b *testing.B) {
+		store, keys := newStore()
+		var n atomic.Int64
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				id := keys[n.Add(1)%ids]
+				store.Record(StatusEvent{ID: id, Type: StatusEventDetail, Key: "cursor", Value: 1})
+			}
+		})
+	})
+
+	b.Run("update, same id", func(b *testing.B) {
+		store, keys := newStore()
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				store.Record(StatusEvent{ID: keys[0], Type: StatusEventDetail, Key: "cursor", Value: 1})
+			}
+		})
+	})
+
+	b.Run("get one", func(b *testing.B) {
+		store, keys := newStore()
+		var n atomic.Int64
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				store.GetOne(keys[n.Add(1)%ids])
+			}
+		})
+	})
+
+	b.Run("get one, with updates", func(b *testing.B) {
+		store, keys := newStore()
+		var n atomic.Int64
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				i := n.Add(1)
+				if i%2 == 0 {
+					store.GetOne(keys[i%ids])
+				} else {
+					store.Record(StatusEvent{ID: keys[i%ids], Type: StatusEventDetail, Key: "cursor", Value: i})
+				}
+			}
+		})
+	})
+
+	b.Run("get", func(b *testing.B) {
+		store, _ := newStore()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			store.Get()
+		}
+	})
 }
@@ -0,0 +1,113 @@
+package runnable
+
+import (
+	"sort"
+)
+
+// Health is the health of a runnable, rolled up over the runnables of its group.
+type Health string
+
+// A runnable that is not running is healthy if its last run exited cleanly, e.g.
+// a one-shot task that returned nil, like for the healthz route of
+// StatusStore.Handler.
+const (
+	// HealthHealthy means the runnable and all its non-optional children are running
+	// or exited cleanly.
+	HealthHealthy Health = "healthy"
+	// HealthDegraded means the runnable is healthy by itself, but one of its
+	// non-optional children is down or degraded.
+	HealthDegraded Health = "degraded"
+	// HealthDown means the runnable is not running, and did not exit cleanly.
+	HealthDown Health = "down"
+)
+
+// StatusNode is a runnable in the tree returned by StatusStore.Tree.
+type StatusNode struct {
+	ID       string        `json:"id"`
+	Status   Status        `json:"status"`
+	Health   Health        `json:"health"`
+	Children []*StatusNode `json:"children,omitempty"`
+}
+
+// Tree returns the runnables of the store as a tree, following the groups they
+// run in (see NewGroupWithOptions), with their health rolled up. Runnables whose
+// group is not in the store are roots. Roots and children are sorted by ID.
+//
+// Example:
+//
+//	for _, node := range store.Tree() {
+//		if node.Health != runnable.HealthHealthy {
+//			log.Printf("%s is %s", node.ID, node.Health)
+//		}
+//	}
+func (s *StatusStore) Tree() []*StatusNode {
+	sm := s.Get()
+
+	nodes := make(map[string]*StatusNode, len(sm))
+	for id, st := range sm {
+		nodes[id] = &StatusNode{ID: id, Status: st}
+	}
+
+	var roots []*StatusNode
+	for _, node := range nodes {
+		if parent, ok := nodes[node.Status.Parent]; ok && parent != node {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	sortStatusNodes(roots)
+	for _, node := range roots {
+		node.rollUp()
+	}
+	return roots
+}
+
+// Health returns the health of the runnable with the given ID, rolled up over the
+// runnables of its group, and false if the runnable is not in the store.
+func (s *StatusStore) Health(id string) (Health, bool) {
+	var find func(nodes []*StatusNode) (Health, bool)
+	find = func(nodes []*StatusNode) (Health, bool) {
+		for _, node := range nodes {
+			if node.ID == id {
+				return node.Health, true
+			}
+			if health, ok := find(node.Children); ok {
+				return health, true
+			}
+		}
+		return "", false
+	}
+	return find(s.Tree())
+}
+
+// rollUp sorts the children of the node and sets the health of the node and its
+// descendants.
+func (n *StatusNode) rollUp() Health {
+	sortStatusNodes(n.Children)
+
+	n.Health = HealthHealthy
+	if !n.Status.Running && !exitedCleanly(n.Status) {
+		n.Health = HealthDown
+	}
+
+	for _, child := range n.Children {
+		health := child.rollUp()
+		if health != HealthHealthy && !child.Status.Optional && n.Health == HealthHealthy {
+			n.Health = HealthDegraded
+		}
+	}
+	return n.Health
+}
+
+// exitedCleanly returns true if the last run of st returned nil or was canceled.
+func exitedCleanly(st Status) bool {
+	return st.ExitReason == ExitReasonSuccess || st.ExitReason == ExitReasonCanceled
+}
+
+func sortStatusNodes(nodes []*StatusNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+}
@@ -0,0 +1,170 @@
+package runnable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusTree(t *testing.T) {
+
+	t.Run("hierarchical ids", func(t *testing.T) {
+		store := NewStatusStore()
+
+		app := NewGroupWithOptions([]Runnable{
+			NewGroupWithOptions([]Runnable{
+				New(func(ctx context.Context) error {
+					return nil
+				}, WithStatus("http", store)),
+			}, WithStatus("api", store)),
+			New(func(ctx context.Context) error {
+				return nil
+			}, WithStatus("worker", store)),
+			NewGroup(
+				New(func(ctx context.Context) error {
+					return nil
+				}, WithStatus("anonymous", store)),
+			),
+		}, WithStatus("app", store))
+		require.NoError(t, app.Run(context.Background()))
+
+		sm := store.Get()
+		assert.Len(t, sm, 5)
+		assert.Equal(t, "", sm["app"].Parent)
+		assert.Equal(t, "app", sm["app/api"].Parent)
+		assert.Equal(t, "app/api", sm["app/api/http"].Parent)
+		assert.Equal(t, "app", sm["app/worker"].Parent)
+		assert.Equal(t, "app", sm["app/anonymous"].Parent)
+
+		tree := store.Tree()
+		require.Len(t, tree, 1)
+		assert.Equal(t, "app", tree[0].ID)
+		require.Len(t, tree[0].Children, 3)
+		assert.Equal(t, "app/anonymous", tree[0].Children[0].ID)
+		assert.Equal(t, "app/api", tree[0].Children[1].ID)
+		assert.Equal(t, "app/worker", tree[0].Children[2].ID)
+		require.Len(t, tree[0].Children[1].Children, 1)
+		assert.Equal(t, "app/api/http", tree[0].Children[1].Children[0].ID)
+	})
+
+	t.Run("group without status", func(t *testing.T) {
+		store := NewStatusStore()
+
+		group := NewGroup(
+			New(func(ctx context.Context) error {
+				return nil
+			}, WithStatus("http", store)),
+		)
+		require.NoError(t, group.Run(context.Background()))
+
+		assert.Contains(t, store.Get(), "http")
+		assert.Len(t, store.Tree(), 1)
+	})
+
+	t.Run("health rollup", func(t *testing.T) {
+		store := NewStatusStore()
+
+		started := make(chan struct{})
+		stopGRPC := make(chan struct{})
+		api := NewGroupWithOptions([]Runnable{
+			New(func(ctx context.Context) error {
+				started <- struct{}{}
+				<-ctx.Done()
+				return nil
+			}, WithStatus("http", store)),
+			New(func(ctx context.Context) error {
+				started <- struct{}{}
+				<-stopGRPC
+				return nil
+			}, WithStatus("grpc", store)),
+			New(func(ctx context.Context) error {
+				return nil
+			}, WithStatus("cache", store), WithOptional()),
+		}, WithStatus("api", store))
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- api.Run(context.Background())
+		}()
+		<-started
+		<-started
+		assert.Eventually(t, func() bool {
+			return !store.Get()["api/cache"].Running
+		}, time.Second, 10*time.Millisecond)
+
+		assert.True(t, store.Get()["api/cache"].Optional)
+		health, ok := store.Health("api")
+		assert.True(t, ok)
+		assert.Equal(t, HealthHealthy, health)
+
+		// a one-shot task that returned nil is healthy
+		health, ok = store.Health("api/cache")
+		assert.True(t, ok)
+		assert.Equal(t, HealthHealthy, health)
+
+		close(stopGRPC)
+		assert.Eventually(t, func() bool {
+			return !store.Get()["api/grpc"].Running
+		}, time.Second, 10*time.Millisecond)
+
+		health, _ = store.Health("api/grpc")
+		assert.Equal(t, HealthHealthy, health)
+		health, _ = store.Health("api")
+		assert.Equal(t, HealthHealthy, health)
+
+		require.NoError(t, api.Stop(context.Background()))
+		require.NoError(t, <-errCh)
+
+		health, _ = store.Health("api")
+		assert.Equal(t, HealthHealthy, health)
+
+		_, ok = store.Health("unknown")
+		assert.False(t, ok)
+	})
+
+	t.Run("degraded child", func(t *testing.T) {
+		store := NewStatusStore()
+		store.Record(StatusEvent{ID: "app", Type: StatusEventStarted, Time: time.Now()})
+		store.Record(StatusEvent{ID: "app/api", Type: StatusEventStarted, Time: time.Now(), Parent: "app"})
+		store.Record(StatusEvent{ID: "app/api/http", Type: StatusEventStarted, Time: time.Now(), Parent: "app/api"})
+		store.Record(StatusEvent{ID: "app/api/http", Type: StatusEventStopped, Time: time.Now()})
+
+		health, _ := store.Health("app/api")
+		assert.Equal(t, HealthDegraded, health)
+		health, _ = store.Health("app")
+		assert.Equal(t, HealthDegraded, health)
+	})
+
+	t.Run("exited child", func(t *testing.T) {
+		store := NewStatusStore()
+		store.Record(StatusEvent{ID: "app", Type: StatusEventStarted, Time: time.Now()})
+		store.Record(StatusEvent{ID: "app/migrate", Type: StatusEventStarted, Time: time.Now(), Parent: "app"})
+		store.Record(StatusEvent{ID: "app/migrate", Type: StatusEventEnded, Time: time.Now()})
+
+		health, _ := store.Health("app/migrate")
+		assert.Equal(t, HealthHealthy, health)
+		health, _ = store.Health("app")
+		assert.Equal(t, HealthHealthy, health)
+
+		store.Record(StatusEvent{ID: "app/migrate", Type: StatusEventStarted, Time: time.Now(), Parent: "app"})
+		store.Record(StatusEvent{ID: "app/migrate", Type: StatusEventEnded, Time: time.Now(), Err: assert.AnError})
+
+		health, _ = store.Health("app/migrate")
+		assert.Equal(t, HealthDown, health)
+		health, _ = store.Health("app")
+		assert.Equal(t, HealthDegraded, health)
+	})
+
+	t.Run("orphans are roots", func(t *testing.T) {
+		store := NewStatusStore()
+		store.Record(StatusEvent{ID: "api/http", Type: StatusEventStarted, Time: time.Now(), Parent: "api"})
+
+		tree := store.Tree()
+		require.Len(t, tree, 1)
+		assert.Equal(t, "api/http", tree[0].ID)
+		assert.Equal(t, HealthHealthy, tree[0].Health)
+	})
+}
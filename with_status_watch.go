@@ -0,0 +1,163 @@
+package runnable
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultWatchBuffer is the default number of changes queued for a receiver of
+// Watch before they are coalesced.
+const DefaultWatchBuffer = 1024
+
+// StatusChange describes a change of the status of a runnable. Old is the zero
+// Status when the runnable was not known to the store before the change, and New
+// is the zero Status when the runnable was removed from the store.
+type StatusChange struct {
+	ID      string
+	Old     Status
+	New     Status
+	Removed bool
+	// Coalesced is the number of changes merged into this one, see Watch.
+	Coalesced int
+}
+
+type watchBuffer int
+
+// WithWatchBuffer sets the number of changes queued for a receiver of Watch before
+// they are coalesced. The default is DefaultWatchBuffer.
+func WithWatchBuffer(n int) StatusStoreOption {
+	return watchBuffer(n)
+}
+
+func (b watchBuffer) applyStore(s *StatusStore) {
+	s.watchBuffer = int(b)
+}
+
+type statusWatcher struct {
+	changes []StatusChange
+	// index is the position of the change of each ID in changes, once coalesced.
+	index  map[string]int
+	limit  int
+	signal chan struct{}
+
+	mu sync.Mutex
+}
+
+// Watch returns a channel that receives every change made to the store from now
+// on, until ctx is done; the channel is then closed. The changes of a runnable are
+// received in order. Changes are queued while the receiver is busy, so a slow
+// receiver never blocks the runnables.
+//
+// Once the queue of a receiver is full (see WithWatchBuffer), its changes are
+// coalesced by ID: the queued changes of a runnable are merged into one, with the
+// Old status of the first change and the New status of the last one, until the
+// receiver catches up. The queue then holds at most one change per runnable, and
+// Coalesced tells how many changes were merged.
+//
+// Example:
+//
+//	for change := range store.Watch(ctx) {
+//		if change.Old.Running && !change.New.Running {
+//			alert(change.ID, change.New.LastError)
+//		}
+//	}
+func (s *StatusStore) Watch(ctx context.Context) <-chan StatusChange {
+	watcher := &statusWatcher{
+		limit:  s.watchBuffer,
+		signal: make(chan struct{}, 1),
+	}
+
+	s.watchersMu.Lock()
+	s.watchers[watcher] = struct{}{}
+	s.watching.Add(1)
+	s.watchersMu.Unlock()
+
+	ch := make(chan StatusChange)
+	go func() {
+		defer close(ch)
+		defer func() {
+			s.watchersMu.Lock()
+			delete(s.watchers, watcher)
+			s.watching.Add(-1)
+			s.watchersMu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-watcher.signal:
+			}
+
+			for _, change := range watcher.pop() {
+				select {
+				case <-ctx.Done():
+					return
+				case ch <- change:
+				}
+			}
+		}
+	}()
+
+	return ch
+}
+
+func (w *statusWatcher) push(change StatusChange) {
+	w.mu.Lock()
+	if w.index == nil && len(w.changes) >= w.limit {
+		w.coalesce()
+	}
+
+	if w.index == nil {
+		w.changes = append(w.changes, change)
+	} else if i, ok := w.index[change.ID]; ok {
+		w.changes[i] = mergeChanges(w.changes[i], change)
+	} else {
+		w.index[change.ID] = len(w.changes)
+		w.changes = append(w.changes, change)
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (w *statusWatcher) pop() []StatusChange {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	changes := w.changes
+	w.changes = nil
+	w.index = nil
+	return changes
+}
+
+// coalesce merges the queued changes of each ID into one, in place. The changes
+// are kept in the order of the first change of each ID.
+func (w *statusWatcher) coalesce() {
+	w.index = make(map[string]int)
+
+	changes := w.changes[:0]
+	for _, change := range w.changes {
+		if i, ok := w.index[change.ID]; ok {
+			changes[i] = mergeChanges(changes[i], change)
+			continue
+		}
+		w.index[change.ID] = len(changes)
+		changes = append(changes, change)
+	}
+	w.changes = changes
+}
+
+// mergeChanges merges next, a later change of the same ID, into prev.
+func mergeChanges(prev, next StatusChange) StatusChange {
+	return StatusChange{
+		ID:        prev.ID,
+		Old:       prev.Old,
+		New:       next.New,
+		Removed:   next.Removed,
+		Coalesced: prev.Coalesced + next.Coalesced + 1,
+	}
+}
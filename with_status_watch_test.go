@@ -0,0 +1,136 @@
+package runnable
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusWatch(t *testing.T) {
+
+	t.Run("watch", func(t *testing.T) {
+		store := NewStatusStore()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		changes := store.Watch(ctx)
+
+		r := New(func(ctx context.Context) error {
+			return assert.AnError
+		}, WithStatus("test", store))
+		require.Error(t, r.Run(context.Background()))
+
+		var received []StatusChange
+		timeout := time.After(time.Second)
+		for len(received) < 3 {
+			select {
+			case change := <-changes:
+				received = append(received, change)
+			case <-timeout:
+				t.Fatalf("received %d changes, expected 3", len(received))
+			}
+		}
+
+		// start
+		assert.Equal(t, "test", received[0].ID)
+		assert.Equal(t, false, received[0].Old.Running)
+		assert.Equal(t, true, received[0].New.Running)
+
+		// run ended
+		assert.Equal(t, true, received[1].Old.Running)
+		assert.Equal(t, false, received[1].New.Running)
+		assert.Nil(t, received[1].Old.LastError)
+		assert.Equal(t, assert.AnError, received[1].New.LastError)
+
+		// stop
+		assert.Equal(t, false, received[2].New.Running)
+	})
+
+	t.Run("slow receiver", func(t *testing.T) {
+		store := NewStatusStore()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		changes := store.Watch(ctx)
+
+		r := New(func(ctx context.Context) error {
+			return nil
+		}, WithStatus("test", store))
+		for i := 0; i < 10; i++ {
+			require.NoError(t, r.Run(context.Background()))
+		}
+
+		restarts := -1
+		for i := 0; i < 30; i++ {
+			change := <-changes
+			if change.New.Running {
+				assert.Equal(t, restarts+1, change.New.Restarts)
+				restarts = change.New.Restarts
+			}
+		}
+		assert.Equal(t, 9, restarts)
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		store := NewStatusStore(WithWatchBuffer(4))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		changes := store.Watch(ctx)
+
+		for _, id := range []string{"a", "b"} {
+			r := New(func(ctx context.Context) error {
+				return nil
+			}, WithStatus(id, store))
+			for i := 0; i < 50; i++ {
+				require.NoError(t, r.Run(context.Background()))
+			}
+		}
+		require.True(t, store.Remove("a"))
+
+		// 2 runnables, 50 runs of 3 changes each, and a removal
+		total := 301
+		received := 0
+		last := map[string]StatusChange{}
+		timeout := time.After(time.Second)
+		for n := 0; n < total; {
+			select {
+			case change := <-changes:
+				n += change.Coalesced + 1
+				received++
+				last[change.ID] = change
+			case <-timeout:
+				t.Fatalf("received %d changes, expected %d", n, total)
+			}
+		}
+
+		assert.Less(t, received, total)
+		assert.True(t, last["a"].Removed)
+		assert.Equal(t, 50, last["b"].New.Runs)
+		assert.Equal(t, false, last["b"].New.Running)
+	})
+
+	t.Run("close on cancel", func(t *testing.T) {
+		store := NewStatusStore()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		changes := store.Watch(ctx)
+		cancel()
+
+		select {
+		case _, ok := <-changes:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("channel not closed")
+		}
+
+		require.Eventually(t, func() bool {
+			store.watchersMu.Lock()
+			defer store.watchersMu.Unlock()
+			return len(store.watchers) == 0 && store.watching.Load() == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,117 @@
+package runnable
+
+import (
+	"context"
+	"errors"
+)
+
+// Tracer creates spans. It is a small interface, so that runnables can be traced
+// without depending on a tracing SDK; see the README for an OpenTelemetry adapter,
+// and RecordingTracer for tests.
+type Tracer interface {
+	// StartSpan starts a span with the given name and attributes, as a child of
+	// the span in ctx, if any. It returns a context holding the new span.
+	StartSpan(ctx context.Context, name string, attrs map[string]interface{}) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttributes sets attributes of the span.
+	SetAttributes(attrs map[string]interface{})
+	// RecordError records an error that occurred during the span.
+	RecordError(err error)
+	// End ends the span.
+	End()
+}
+
+// Names of the spans created by WithTracer.
+const (
+	SpanNameRun     = "runnable.run"
+	SpanNameAttempt = "runnable.attempt"
+)
+
+type withTracer struct {
+	tracer Tracer
+
+	owner *runnable
+
+	// the spans are only accessed by the goroutine running the runnable
+	runSpan     Span
+	attemptSpan Span
+}
+
+// WithTracer traces the runnable with tracer. Every Run is a span named
+// SpanNameRun, with a child span named SpanNameAttempt for each of its attempts
+// (see WithRetry). Spans have the "runnable.id" attribute, attempt spans the
+// "runnable.attempt" attribute, and ended spans the "runnable.exit_reason"
+// attribute. Errors and panics are recorded on the span they ended.
+//
+// The context of each attempt of runFunc holds its attempt span, so the spans
+// started by runFunc are children of the attempt they belong to.
+//
+// Example:
+//
+//	r := runnable.New(run, runnable.WithStatus("worker", store), runnable.WithTracer(tracer))
+func WithTracer(tracer Tracer) Option {
+	return optionFunc(func(r *runnable) {
+		w := &withTracer{
+			tracer: tracer,
+			owner:  r,
+		}
+
+		r.runHooks = append(r.runHooks, w)
+		r.chainHooks(nil, w.attemptStopped)
+	})
+}
+
+// beforeAttempt starts the span of the attempt, as a child of the run span, and
+// returns the context of the attempt holding it.
+func (w *withTracer) beforeAttempt(ctx context.Context) context.Context {
+	ctx, w.attemptSpan = w.tracer.StartSpan(ctx, SpanNameAttempt, map[string]interface{}{
+		"runnable.id":      w.owner.id,
+		"runnable.attempt": w.owner.attempt,
+	})
+	return ctx
+}
+
+func (w *withTracer) attemptStopped(err error) {
+	if w.attemptSpan != nil {
+		endSpan(w.attemptSpan, err, nil)
+		w.attemptSpan = nil
+	}
+}
+
+func (w *withTracer) beforeRun(ctx context.Context) (context.Context, error) {
+	ctx, w.runSpan = w.tracer.StartSpan(ctx, SpanNameRun, map[string]interface{}{
+		"runnable.id": w.owner.id,
+	})
+	return ctx, nil
+}
+
+func (w *withTracer) afterRun(ctx context.Context, err error) {
+	endSpan(w.runSpan, err, map[string]interface{}{
+		"runnable.attempts": w.owner.attempt,
+	})
+	w.runSpan = nil
+}
+
+// endSpan records the exit reason and error of err on span, and ends it.
+func endSpan(span Span, err error, attrs map[string]interface{}) {
+	if attrs == nil {
+		attrs = make(map[string]interface{})
+	}
+
+	reason := exitReasonOf(err)
+	attrs["runnable.exit_reason"] = string(reason)
+
+	var p *PanicError
+	if errors.As(err, &p) {
+		attrs["runnable.panic"] = p.Value
+	}
+	span.SetAttributes(attrs)
+
+	if reason == ExitReasonError || reason == ExitReasonPanic {
+		span.RecordError(err)
+	}
+	span.End()
+}
@@ -0,0 +1,125 @@
+package runnable
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTracer(t *testing.T) {
+
+	t.Run("with tracer", func(t *testing.T) {
+		tracer := NewRecordingTracer()
+
+		r := New(func(ctx context.Context) error {
+			_, span := tracer.StartSpan(ctx, "work", nil)
+			span.End()
+			return nil
+		}, WithTracer(tracer), WithStatus("test", NewStatusStore()))
+		require.NoError(t, r.Run(context.Background()))
+
+		spans := tracer.Spans()
+		require.Len(t, spans, 3)
+
+		run, attempt, work := spans[0], spans[1], spans[2]
+		assert.Equal(t, SpanNameRun, run.Name)
+		assert.Equal(t, 0, run.ParentID)
+		assert.True(t, run.Ended)
+		assert.Equal(t, "test", run.Attributes["runnable.id"])
+		assert.Equal(t, 1, run.Attributes["runnable.attempts"])
+		assert.Equal(t, "success", run.Attributes["runnable.exit_reason"])
+		assert.Empty(t, run.Errors)
+
+		assert.Equal(t, SpanNameAttempt, attempt.Name)
+		assert.Equal(t, run.ID, attempt.ParentID)
+		assert.True(t, attempt.Ended)
+		assert.Equal(t, 1, attempt.Attributes["runnable.attempt"])
+
+		assert.Equal(t, "work", work.Name)
+		assert.Equal(t, attempt.ID, work.ParentID)
+	})
+
+	t.Run("with tracer, with retry", func(t *testing.T) {
+		for _, tracerFirst := range []bool{true, false} {
+			tracer := NewRecordingTracer()
+
+			opts := []Option{WithTracer(tracer), WithRetry(3, ResetNever)}
+			if !tracerFirst {
+				opts[0], opts[1] = opts[1], opts[0]
+			}
+
+			attempts := 0
+			r := New(func(ctx context.Context) error {
+				attempts++
+				_, span := tracer.StartSpan(ctx, "work", nil)
+				span.End()
+				if attempts < 3 {
+					return assert.AnError
+				}
+				return nil
+			}, opts...)
+			require.NoError(t, r.Run(context.Background()))
+
+			spans := tracer.Spans()
+			require.Len(t, spans, 7)
+			assert.Equal(t, 3, spans[0].Attributes["runnable.attempts"])
+			assert.Empty(t, spans[0].Errors)
+
+			// each attempt is followed by the span started by its runFunc
+			for i := 0; i < 3; i++ {
+				attempt, work := spans[1+2*i], spans[2+2*i]
+				assert.Equal(t, SpanNameAttempt, attempt.Name)
+				assert.Equal(t, spans[0].ID, attempt.ParentID)
+				assert.Equal(t, i+1, attempt.Attributes["runnable.attempt"])
+				assert.True(t, attempt.Ended)
+				assert.Equal(t, "work", work.Name)
+				assert.Equal(t, attempt.ID, work.ParentID)
+			}
+			assert.Equal(t, []error{assert.AnError}, spans[1].Errors)
+			assert.Equal(t, "error", spans[1].Attributes["runnable.exit_reason"])
+			assert.Equal(t, []error{assert.AnError}, spans[3].Errors)
+			assert.Empty(t, spans[5].Errors)
+			assert.Equal(t, "success", spans[5].Attributes["runnable.exit_reason"])
+		}
+	})
+
+	t.Run("with tracer, panic", func(t *testing.T) {
+		tracer := NewRecordingTracer()
+
+		r := New(func(ctx context.Context) error {
+			panic("boom")
+		}, WithTracer(tracer), WithPanicReporter(PanicReporterFunc(func(ctx context.Context, p *PanicError) {})))
+		require.Error(t, r.Run(context.Background()))
+
+		spans := tracer.Spans()
+		require.Len(t, spans, 2)
+		for _, span := range spans {
+			assert.Equal(t, "panic", span.Attributes["runnable.exit_reason"])
+			assert.Equal(t, "boom", span.Attributes["runnable.panic"])
+			require.Len(t, span.Errors, 1)
+
+			var p *PanicError
+			assert.ErrorAs(t, span.Errors[0], &p)
+		}
+	})
+
+	t.Run("with tracer, group", func(t *testing.T) {
+		tracer := NewRecordingTracer()
+		store := NewStatusStore()
+
+		group := NewGroupWithOptions([]Runnable{
+			New(func(ctx context.Context) error {
+				return nil
+			}, WithTracer(tracer), WithStatus("http", store)),
+		}, WithStatus("api", store), WithTracer(tracer))
+		require.NoError(t, group.Run(context.Background()))
+
+		spans := tracer.Spans()
+		require.Len(t, spans, 4)
+		assert.Equal(t, "api", spans[0].Attributes["runnable.id"])
+		assert.Equal(t, "api/http", spans[2].Attributes["runnable.id"])
+		assert.Equal(t, spans[1].ID, spans[2].ParentID)
+	})
+}